@@ -0,0 +1,110 @@
+// Copyright 2023 Meelis Utt. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package tlog
+
+import (
+	"strings"
+	"testing"
+)
+
+// indentUnit is the indentation of the log entries per subtest depth.
+const indentUnit = "    "
+
+// Child creates a new logger for the subtest t, that is a child of the logger.
+// The child logger uses the parent's io.Writer, Formatter, LocationFormat, Mode, Limit, eager formatting (see Logger.FormatsEagerly), fields, caller skip and helper functions, and spills when the parent spills (see Logger.Spill).
+//
+// When the subtest fails or panics, the child logger outputs the log entries that its ancestor loggers made
+// before the subtest started and that are not yet outputted, followed by its own log entries.
+// The log entries are indented by the subtest depth.
+// As usual, nothing is outputted when the subtest passes.
+func (sl *Logger) Child(t testing.TB) *Logger {
+	t.Helper()
+	sl.mu.RLock()
+	cl := newLogger(t, sl.writesTo)
+	cl.formatter = sl.formatter
+	cl.locationFormat = sl.locationFormat
+	cl.helpers = sl.helpers
+	cl.mode = sl.mode
+	cl.limit = sl.limit
+	cl.eager.Store(sl.eager.Load())
+	cl.parent = sl
+	cl.parentMark = len(sl.logs) + sl.dropped
+	cl.depth = sl.depth + 1
+	spill := sl.spill != nil
+	sl.mu.RUnlock()
+
+	cl.fields = sl.fields
+	cl.skip = sl.skip
+	if spill {
+		cl.Spill()
+	}
+	return cl
+}
+
+// Run runs f as a subtest of the logger's test called name, similarly to testing.T.Run and testing.B.Run.
+// f is called with a child logger of the subtest, see Logger.Child, and the subtest itself is available through Logger.TB.
+// It reports whether f succeeded.
+//
+// Run panics when the logger was not created with *testing.T or *testing.B.
+func (sl *Logger) Run(name string, f func(*Logger)) bool {
+	sl.t.Helper()
+	switch t := sl.t.(type) {
+	case *testing.T:
+		return t.Run(name, func(st *testing.T) {
+			f(sl.Child(st))
+		})
+	case *testing.B:
+		return t.Run(name, func(sb *testing.B) {
+			f(sl.Child(sb))
+		})
+	default:
+		panic("tlog: Logger.Run called on a logger not created with *testing.T or *testing.B")
+	}
+}
+
+// TB returns the test, benchmark or fuzz test of the logger, eg to fail the subtest inside Logger.Run.
+func (sl *Logger) TB() testing.TB {
+	return sl.t
+}
+
+// printContext outputs the log entries that the ancestor loggers made before this logger was created
+// and that are not yet outputted, starting from the root logger.
+// The outputted entries are marked, so that the ancestor loggers don't output them again.
+func (sl *Logger) printContext() {
+	sl.t.Helper()
+	var ancestors []*Logger
+	var marks []int
+	for l := sl; l.parent != nil; l = l.parent {
+		ancestors = append([]*Logger{l.parent}, ancestors...)
+		marks = append([]int{l.parentMark}, marks...)
+	}
+	for i, a := range ancestors {
+		a.mu.Lock()
+		for _, log := range a.logs[:a.markIndex(marks[i])] {
+			if !log.printed {
+				a.output(a.writesTo, log)
+				log.printed = true
+			}
+		}
+		a.mu.Unlock()
+	}
+}
+
+// indent prefixes each line of the text with the indentation of the given depth.
+func indent(text string, depth int) string {
+	if depth == 0 {
+		return text
+	}
+	prefix := strings.Repeat(indentUnit, depth)
+	lines := strings.SplitAfter(text, "\n")
+	var sb strings.Builder
+	for _, line := range lines {
+		if line != "" {
+			sb.WriteString(prefix)
+			sb.WriteString(line)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,152 @@
+// Copyright 2023 Meelis Utt. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package tlog
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// liveLoggers contains the loggers of the tests that are currently running, in the order of creation.
+var liveLoggers struct {
+	sync.Mutex
+	loggers []*Logger
+}
+
+// register adds the logger to the live loggers.
+func (sl *Logger) register() {
+	liveLoggers.Lock()
+	defer liveLoggers.Unlock()
+	liveLoggers.loggers = append(liveLoggers.loggers, sl)
+}
+
+// unregister removes the logger from the live loggers.
+func (sl *Logger) unregister() {
+	liveLoggers.Lock()
+	defer liveLoggers.Unlock()
+	for i, l := range liveLoggers.loggers {
+		if l == sl {
+			liveLoggers.loggers = append(liveLoggers.loggers[:i], liveLoggers.loggers[i+1:]...)
+			return
+		}
+	}
+}
+
+// DumpLiveLoggers outputs the log entries of every logger, whose test is still running, to the logger's io.Writer.
+// The log entries of each logger are preceded by a LevelWarn entry with the location "<dump>", stating that the test is still running.
+// The outputted log entries are removed from the loggers, so that they are not outputted again when the test ends.
+// Loggers without log entries to output are skipped.
+//
+// DumpLiveLoggers is used by InstallDumpHook, but it can be called directly as well, eg from a test watchdog.
+func DumpLiveLoggers() {
+	liveLoggers.Lock()
+	loggers := append([]*Logger(nil), liveLoggers.loggers...)
+	liveLoggers.Unlock()
+	for _, sl := range loggers {
+		sl.dump()
+	}
+}
+
+// dump outputs the log entries of the still running test that are not yet outputted, preceded by the marker entry.
+// The outputted log entries are marked, so that they are not outputted again when the test ends.
+func (sl *Logger) dump() {
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+	var pending []*Entry
+	for _, log := range sl.withDropped(sl.logs) {
+		if !log.printed {
+			pending = append(pending, log)
+		}
+	}
+	if len(pending) == 0 {
+		return
+	}
+	sl.output(sl.writesTo, &Entry{
+		Time:     time.Now(),
+		Location: "<dump>",
+		Name:     sl.t.Name(),
+		Level:    LevelWarn,
+		Message:  "test is still running, outputting buffered log entries",
+	})
+	for _, log := range pending {
+		sl.output(sl.writesTo, log)
+		log.printed = true
+	}
+	// NOTE: the log entries are kept, so that the marks of the child loggers and the benchmark loop stay valid,
+	// see Logger.markIndex.
+	sl.reported = sl.dropped
+}
+
+// InstallDumpHook makes sure that the buffered log entries of the still running tests are not lost,
+// when the test binary is killed by SIGQUIT or by the `go test -timeout` deadline, see DumpLiveLoggers.
+//
+// On SIGQUIT, the log entries are outputted, followed by the stack traces of all goroutines written to os.Stderr,
+// and the test binary exits with code 2, similarly to the default SIGQUIT behavior.
+// When the -test.timeout flag is set, the log entries are outputted margin before the timeout,
+// so that they appear before the goroutine dump of the timeout panic.
+// On plan9, which doesn't have SIGQUIT, only the timeout hook is installed.
+// The returned function uninstalls the hook.
+//
+// To read the -test.timeout flag, InstallDumpHook parses the command-line flags when they are not yet parsed (see flag.Parse),
+// so any custom flags of the test binary must be defined before calling it.
+//
+// InstallDumpHook is meant to be called from TestMain before running the tests:
+//
+//	func TestMain(m *testing.M) {
+//		tlog.InstallDumpHook(time.Second)
+//		os.Exit(m.Run())
+//	}
+func InstallDumpHook(margin time.Duration) func() {
+	if !flag.Parsed() {
+		flag.Parse()
+	}
+	var timer *time.Timer
+	if f := flag.Lookup("test.timeout"); f != nil {
+		if timeout, ok := f.Value.(flag.Getter).Get().(time.Duration); ok && timeout > 0 {
+			timer = time.AfterFunc(max(timeout-margin, 0), DumpLiveLoggers)
+		}
+	}
+
+	sigs := make(chan os.Signal, 1)
+	notifyQuit(sigs)
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-sigs:
+			DumpLiveLoggers()
+			fmt.Fprintf(os.Stderr, "SIGQUIT: quit\n\n%s", allStacks())
+			os.Exit(2)
+		case <-done:
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(sigs)
+			if timer != nil {
+				timer.Stop()
+			}
+			close(done)
+		})
+	}
+}
+
+// allStacks returns the stack traces of all goroutines.
+func allStacks() []byte {
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			return buf[:n]
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
@@ -0,0 +1,10 @@
+// Copyright 2023 Meelis Utt. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package tlog
+
+import "os"
+
+// notifyQuit does nothing, since there is no SIGQUIT on plan9, see InstallDumpHook.
+func notifyQuit(sigs chan<- os.Signal) {}
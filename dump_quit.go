@@ -0,0 +1,18 @@
+// Copyright 2023 Meelis Utt. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+//go:build !plan9
+
+package tlog
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// notifyQuit relays SIGQUIT to sigs, see InstallDumpHook.
+func notifyQuit(sigs chan<- os.Signal) {
+	signal.Notify(sigs, syscall.SIGQUIT)
+}
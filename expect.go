@@ -0,0 +1,162 @@
+// Copyright 2023 Meelis Utt. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package tlog
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// maxReportedEntries is the maximum number of matching and nearest log entries shown when a log assertion fails.
+const maxReportedEntries = 5
+
+// Matcher selects log entries for the log assertions, see Logger.ExpectEntry.
+// Query is a Matcher, that also shows the log entries nearest to matching it when the assertion fails.
+type Matcher interface {
+	Match(entry *Entry) bool
+}
+
+// MatcherFunc is an adapter to use ordinary functions as Matchers.
+type MatcherFunc func(entry *Entry) bool
+
+// Match reports whether f(entry) is true.
+func (f MatcherFunc) Match(entry *Entry) bool {
+	return f(entry)
+}
+
+// expectation is a log assertion, that the number of log entries matching the matcher is between min and max.
+type expectation struct {
+	matcher Matcher
+	min     int
+	max     int                  // negative means no upper bound.
+	pcs     [callerDepth]uintptr // program counters of the callers, used to report the location of the assertion.
+}
+
+// expect adds the log assertion to be checked at the end of the test.
+func (sl *Logger) expect(matcher Matcher, min int, max int) {
+	e := expectation{matcher: matcher, min: min, max: max, pcs: callers()}
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+	sl.expectations = append(sl.expectations, e)
+}
+
+// ExpectEntry asserts that at least one log entry matching the matcher is recorded during the test.
+// The log assertions are checked at the end of the test, before deciding whether to output the log entries,
+// so that a failed assertion fails the test and outputs the log entries.
+// The failure message shows the log entries nearest to matching the matcher, when the matcher is a Query.
+// The log entries dropped due to the logger's limits are not counted (see Logger.LimitsTo).
+func (sl *Logger) ExpectEntry(matcher Matcher) {
+	sl.expect(matcher, 1, -1)
+}
+
+// ExpectCount asserts that exactly n log entries matching the matcher are recorded during the test, see Logger.ExpectEntry.
+func (sl *Logger) ExpectCount(matcher Matcher, n int) {
+	sl.expect(matcher, n, n)
+}
+
+// ForbidEntry asserts that no log entries matching the matcher are recorded during the test, see Logger.ExpectEntry.
+// The failure message shows the matching log entries.
+func (sl *Logger) ForbidEntry(matcher Matcher) {
+	sl.expect(matcher, 0, 0)
+}
+
+// checkExpectations checks the log assertions and fails the test with a message for each failed assertion.
+// Each matcher is run on its own copies of the log entries without holding the logger's lock,
+// so that the matchers can use the logger and can't modify the log entries seen by the logger or the other matchers.
+func (sl *Logger) checkExpectations() {
+	sl.t.Helper()
+	sl.mu.RLock()
+	expectations := slices.Clone(sl.expectations)
+	locations := make([]string, len(expectations))
+	for i, e := range expectations {
+		locations[i] = framesLocation(e.pcs[:], 0, sl.locationFormat, sl.helpers)
+	}
+	sl.mu.RUnlock()
+
+	for i, e := range expectations {
+		if msg := checkExpectation(e, locations[i], sl.snapshot()); msg != "" {
+			sl.t.Error(msg)
+		}
+	}
+}
+
+// checkExpectation returns the failure message of the log assertion made at the location,
+// or an empty string when the assertion holds for the log entries.
+func checkExpectation(e expectation, location string, entries []*Entry) string {
+	var matching, other []*Entry
+	for _, entry := range entries {
+		if e.matcher.Match(entry) {
+			matching = append(matching, entry)
+		} else {
+			other = append(other, entry)
+		}
+	}
+	count := len(matching)
+	if count >= e.min && (e.max < 0 || count <= e.max) {
+		return ""
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%v: expected %v log entries matching %v, got %v",
+		location, expectedCount(e), describeMatcher(e.matcher), count)
+	writeEntries(&sb, "matching", matching)
+	if q, ok := e.matcher.(Query); ok && count < e.min {
+		writeEntries(&sb, "nearest", nearest(q, other))
+	}
+	return sb.String()
+}
+
+// expectedCount describes the expected number of matching log entries, eg "at least 1".
+func expectedCount(e expectation) string {
+	switch {
+	case e.max < 0:
+		return fmt.Sprintf("at least %v", e.min)
+	case e.min == e.max:
+		return fmt.Sprint(e.min)
+	default:
+		return fmt.Sprintf("%v to %v", e.min, e.max)
+	}
+}
+
+// describeMatcher describes the matcher using its String method, or its type when it doesn't have one.
+func describeMatcher(m Matcher) string {
+	if s, ok := m.(fmt.Stringer); ok {
+		return s.String()
+	}
+	return fmt.Sprintf("%T", m)
+}
+
+// nearest returns the log entries nearest to matching the query, ie the ones matching the most of its conditions.
+func nearest(q Query, entries []*Entry) []*Entry {
+	scores := make(map[*Entry]int, len(entries))
+	var candidates []*Entry
+	for _, entry := range entries {
+		if score := q.score(entry); score > 0 {
+			scores[entry] = score
+			candidates = append(candidates, entry)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return scores[candidates[i]] > scores[candidates[j]]
+	})
+	return candidates
+}
+
+// writeEntries writes the title and up to maxReportedEntries log entries, indented, to the failure message.
+func writeEntries(sb *strings.Builder, title string, entries []*Entry) {
+	if len(entries) == 0 {
+		return
+	}
+	fmt.Fprintf(sb, "\n%v:", title)
+	for i, entry := range entries {
+		if i == maxReportedEntries {
+			fmt.Fprintf(sb, "\n%v... and %v more", indentUnit, len(entries)-i)
+			break
+		}
+		sb.WriteString("\n" + strings.TrimSuffix(indent(entry.String(), 1), "\n"))
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright 2023 Meelis Utt. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package tlog
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// badKey is the key used for values that are not preceded by a string key.
+const badKey = "!BADKEY"
+
+// Field is a structured key/value attribute of a log entry.
+type Field struct {
+	Key   string // Attribute's key.
+	Value any    // Attribute's value, stored as it was provided.
+}
+
+// String returns field in the k=v form.
+// The value is quoted when it's empty or contains spaces, quotes, '=' or ':' characters.
+func (f Field) String() string {
+	return fmt.Sprintf("%v=%v", f.Key, quoteValue(fmt.Sprint(f.Value)))
+}
+
+// quoteValue quotes the value, if it can't be rendered unambiguously in the k=v form.
+func quoteValue(value string) string {
+	needsQuoting := value == "" || strings.IndexFunc(value, func(r rune) bool {
+		return unicode.IsSpace(r) || !unicode.IsPrint(r) || r == '"' || r == '=' || r == ':'
+	}) >= 0
+	if needsQuoting {
+		return strconv.Quote(value)
+	}
+	return value
+}
+
+// formatFields returns fields in the stable ' k=v k=v' form, in the order they were provided.
+func formatFields(fields []Field) string {
+	var sb strings.Builder
+	for _, f := range fields {
+		sb.WriteString(" ")
+		sb.WriteString(f.String())
+	}
+	return sb.String()
+}
+
+// joinFields returns a new list containing fields from both lists, without modifying either of them.
+func joinFields(fields []Field, more []Field) []Field {
+	if len(more) == 0 {
+		return fields
+	}
+	joined := make([]Field, 0, len(fields)+len(more))
+	joined = append(joined, fields...)
+	return append(joined, more...)
+}
+
+// argsToFields converts alternating keys and values to fields, similarly to log/slog.
+// Arguments that already are fields are used as they are.
+// A value without a string key is stored with the key "!BADKEY".
+func argsToFields(args []any) []Field {
+	var fields []Field
+	for i := 0; i < len(args); i++ {
+		switch arg := args[i].(type) {
+		case Field:
+			fields = append(fields, arg)
+		case string:
+			if i+1 == len(args) {
+				fields = append(fields, Field{Key: badKey, Value: arg})
+				continue
+			}
+			fields = append(fields, Field{Key: arg, Value: args[i+1]})
+			i++
+		default:
+			fields = append(fields, Field{Key: badKey, Value: arg})
+		}
+	}
+	return fields
+}
+
+// With returns a logger that adds the given fields to each log entry it makes.
+// The fields are provided as alternating keys and values (eg With("user", id)) or as Field values.
+// The returned logger shares the log entries and settings with the logger it was derived from.
+func (sl *Logger) With(args ...any) *Logger {
+	return &Logger{
+		state:  sl.state,
+		fields: joinFields(sl.fields, argsToFields(args)),
+		skip:   sl.skip,
+	}
+}
+
+// Logw records the message in a new log entry with the given fields.
+// The fields are provided as alternating keys and values (eg Logw("msg", "user", id)) or as Field values.
+// The entry is only outputted when the test fails or panics.
+func (sl *Logger) Logw(msg string, args ...any) {
+	sl.t.Helper()
+	sl.logf(LevelInfo, argsToFields(args), "%s", msg)
+}
@@ -0,0 +1,71 @@
+// Copyright 2023 Meelis Utt. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package tlog
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Formatter formats log entries, when they are outputted by the logger.
+type Formatter interface {
+	// Format writes the formatted log entry, including the final newline, to the io.Writer.
+	// It returns the number of bytes written and any write error.
+	Format(wt io.Writer, entry *Entry) (int, error)
+}
+
+// DefaultFormatter is the Formatter used by loggers that don't have a Formatter set (see Logger.FormatsWith).
+// It should be changed before the tests start, eg in TestMain.
+var DefaultFormatter Formatter = TextFormatter{}
+
+// TextFormatter formats log entries as human readable text.
+// The format used is: <timestamp> <location> [<testname>] <level>[ <key>=<value>...]: <message>
+type TextFormatter struct{}
+
+// Format writes the log entry as text.
+func (TextFormatter) Format(wt io.Writer, entry *Entry) (int, error) {
+	return fmt.Fprintf(wt,
+		"%v %v %v %v\n",
+		entry.Time.UTC().Format(textTimeLayout),
+		entry.Location,
+		fmt.Sprintf("[%v] %v%v:", entry.Name, entry.Level, formatFields(entry.Fields)),
+		entry.Message,
+	)
+}
+
+// JSONFormatter formats log entries as JSON Lines, ie one JSON object per line.
+// The schema of the JSON object is described in Entry.MarshalJSON and the log entries can be decoded using DecodeJSON.
+type JSONFormatter struct{}
+
+// Format writes the log entry as a JSON object on a single line.
+func (JSONFormatter) Format(wt io.Writer, entry *Entry) (int, error) {
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return 0, err
+	}
+	return wt.Write(append(b, '\n'))
+}
+
+// LogfmtFormatter formats log entries in the logfmt format, ie as space separated key=value pairs on a single line.
+// Entry fields are appended after the entry's own keys: time, level, location, name and msg.
+type LogfmtFormatter struct{}
+
+// Format writes the log entry in the logfmt format.
+func (LogfmtFormatter) Format(wt io.Writer, entry *Entry) (int, error) {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "time=%v level=%v location=%v name=%v msg=%v",
+		entry.Time.UTC().Format(time.RFC3339Nano),
+		entry.Level,
+		quoteValue(entry.Location),
+		quoteValue(entry.Name),
+		quoteValue(entry.Message),
+	)
+	sb.WriteString(formatFields(entry.Fields))
+	sb.WriteString("\n")
+	return io.WriteString(wt, sb.String())
+}
@@ -0,0 +1,66 @@
+// Copyright 2023 Meelis Utt. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package tlog_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moledoc/tlog"
+)
+
+var formatTestEntry = &tlog.Entry{
+	Time:     time.Date(2023, 3, 21, 22, 14, 1, 982000000, time.UTC),
+	Location: "/foo/bar/baz_test.go:54",
+	Name:     "TestXxx",
+	Level:    tlog.LevelWarn,
+	Message:  "hello world",
+	Fields: []tlog.Field{
+		{Key: "user", Value: 42},
+		{Key: "err", Value: errors.New("failed: timeout")},
+	},
+}
+
+// TestFormatters checks that each formatter outputs the expected format.
+func TestFormatters(t *testing.T) {
+	tests := []struct {
+		name      string
+		formatter tlog.Formatter
+		expected  string
+	}{
+		{
+			name:      "text",
+			formatter: tlog.TextFormatter{},
+			expected:  "2023-03-21 22:14:01.982 /foo/bar/baz_test.go:54 [TestXxx] WARN user=42 err=\"failed: timeout\": hello world\n",
+		},
+		{
+			name:      "json",
+			formatter: tlog.JSONFormatter{},
+			expected:  `{"time":"2023-03-21T22:14:01.982Z","file":"/foo/bar/baz_test.go","line":54,"name":"TestXxx","level":"WARN","message":"hello world","fields":[{"key":"user","type":"int","value":42},{"key":"err","type":"error","value":"failed: timeout"}]}` + "\n",
+		},
+		{
+			name:      "logfmt",
+			formatter: tlog.LogfmtFormatter{},
+			expected:  "time=2023-03-21T22:14:01.982Z level=WARN location=\"/foo/bar/baz_test.go:54\" name=TestXxx msg=\"hello world\" user=42 err=\"failed: timeout\"\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sb strings.Builder
+			n, err := tt.formatter.Format(&sb, formatTestEntry)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if sb.String() != tt.expected {
+				t.Errorf("expected %q, but got %q", tt.expected, sb.String())
+			}
+			if n != len(tt.expected) {
+				t.Errorf("expected %v bytes written, but got %v", len(tt.expected), n)
+			}
+		})
+	}
+}
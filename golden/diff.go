@@ -0,0 +1,212 @@
+// Copyright 2023 Meelis Utt. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package golden
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ANSI escape codes used to color the unified diff.
+const (
+	colorReset = "\x1b[0m"
+	colorRed   = "\x1b[31m"
+	colorGreen = "\x1b[32m"
+	colorCyan  = "\x1b[36m"
+)
+
+// DefaultContext is the default number of unchanged lines shown around the changes in the unified diff.
+const DefaultContext = 3
+
+// opKind is the kind of an edit operation, that turns the expected lines to the actual lines.
+type opKind int
+
+const (
+	opEqual opKind = iota
+	opDelete
+	opInsert
+)
+
+// edit is an edit operation on a line.
+// aIdx is the index of the line in the expected lines and bIdx is the index in the actual lines,
+// they point to the position before the line when the line is not in the corresponding list.
+type edit struct {
+	kind opKind
+	aIdx int
+	bIdx int
+	line string
+}
+
+// Diff returns the unified diff between the expected and actual lines,
+// showing context number of unchanged lines around the changes.
+// When color is set, deleted lines, inserted lines and hunk headers are colored with ANSI escape codes.
+// It returns an empty string when the lines are equal.
+//
+// The diff is computed using the Myers' algorithm, so that the order and duplicates of the lines are preserved.
+func Diff(expected []string, actual []string, context int, color bool) string {
+	edits := myers(expected, actual)
+	hunks := splitHunks(edits, context)
+	if len(hunks) == 0 {
+		return ""
+	}
+	paint := func(code string, s string) string {
+		if !color {
+			return s
+		}
+		return code + s + colorReset
+	}
+
+	var sb strings.Builder
+	sb.WriteString(paint(colorRed, "--- expected") + "\n")
+	sb.WriteString(paint(colorGreen, "+++ actual") + "\n")
+	for _, hunk := range hunks {
+		aStart, aLen, bStart, bLen := hunkRange(hunk)
+		sb.WriteString(paint(colorCyan, fmt.Sprintf("@@ -%v +%v @@", formatRange(aStart, aLen), formatRange(bStart, bLen))) + "\n")
+		for _, e := range hunk {
+			switch e.kind {
+			case opEqual:
+				sb.WriteString(" " + e.line + "\n")
+			case opDelete:
+				sb.WriteString(paint(colorRed, "-"+e.line) + "\n")
+			case opInsert:
+				sb.WriteString(paint(colorGreen, "+"+e.line) + "\n")
+			}
+		}
+	}
+	return sb.String()
+}
+
+// myers computes the shortest edit script, that turns lines a to lines b, using the Myers' O(ND) algorithm.
+func myers(a []string, b []string) []edit {
+	n, m := len(a), len(b)
+	max := n + m
+	offset := max + 1
+	v := make([]int, 2*max+3)
+	// NOTE: trace[d] contains the furthest reaching x values for diagonals -d-1..d+1 before the round d.
+	var trace [][]int
+	for d := 0; d <= max; d++ {
+		trace = append(trace, append([]int(nil), v[offset-d-1:offset+d+2]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return backtrack(a, b, trace)
+			}
+		}
+	}
+	return nil
+}
+
+// backtrack walks the trace of the Myers' algorithm from the end to the beginning and returns the edits in order.
+func backtrack(a []string, b []string, trace [][]int) []edit {
+	var edits []edit
+	x, y := len(a), len(b)
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := func(k int) int { return trace[d][k+d+1] }
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && v(k-1) < v(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v(prevK)
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x--
+			y--
+			edits = append(edits, edit{kind: opEqual, aIdx: x, bIdx: y, line: a[x]})
+		}
+		if d > 0 {
+			if x == prevX {
+				edits = append(edits, edit{kind: opInsert, aIdx: x, bIdx: prevY, line: b[prevY]})
+			} else {
+				edits = append(edits, edit{kind: opDelete, aIdx: prevX, bIdx: y, line: a[prevX]})
+			}
+		}
+		x, y = prevX, prevY
+	}
+	for i, j := 0, len(edits)-1; i < j; i, j = i+1, j-1 {
+		edits[i], edits[j] = edits[j], edits[i]
+	}
+	return edits
+}
+
+// splitHunks groups the changes with context number of unchanged lines around them into hunks.
+// Changes that are separated by at most 2*context unchanged lines are in the same hunk.
+func splitHunks(edits []edit, context int) [][]edit {
+	if context < 0 {
+		context = 0
+	}
+	var hunks [][]edit
+	start, end := -1, -1 // NOTE: range of the current hunk in edits, end is exclusive
+	for i, e := range edits {
+		if e.kind == opEqual {
+			continue
+		}
+		from := i - context
+		if from < 0 {
+			from = 0
+		}
+		if start >= 0 && from > end {
+			hunks = append(hunks, edits[start:end])
+			start = -1
+		}
+		if start < 0 {
+			start = from
+		}
+		end = i + 1 + context
+		if end > len(edits) {
+			end = len(edits)
+		}
+	}
+	if start >= 0 {
+		hunks = append(hunks, edits[start:end])
+	}
+	return hunks
+}
+
+// hunkRange returns the 1-based start line and the number of lines of the hunk in the expected and actual lines.
+// When the hunk has no lines in one of them, the start line is the line before the hunk, as in the unified diff format.
+func hunkRange(hunk []edit) (int, int, int, int) {
+	var aLen, bLen int
+	for _, e := range hunk {
+		switch e.kind {
+		case opEqual:
+			aLen++
+			bLen++
+		case opDelete:
+			aLen++
+		case opInsert:
+			bLen++
+		}
+	}
+	aStart, bStart := hunk[0].aIdx+1, hunk[0].bIdx+1
+	if aLen == 0 {
+		aStart--
+	}
+	if bLen == 0 {
+		bStart--
+	}
+	return aStart, aLen, bStart, bLen
+}
+
+// formatRange formats the hunk range as in the unified diff format, ie the length is omitted when it's 1.
+func formatRange(start int, length int) string {
+	if length == 1 {
+		return fmt.Sprint(start)
+	}
+	return fmt.Sprintf("%v,%v", start, length)
+}
@@ -0,0 +1,84 @@
+// Copyright 2023 Meelis Utt. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package golden_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/moledoc/tlog/golden"
+)
+
+// TestDiff checks that the unified diff preserves the order and duplicates of the lines.
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+		actual   string
+		context  int
+		diff     string
+	}{
+		{
+			name:     "equal",
+			expected: "a\nb\nc",
+			actual:   "a\nb\nc",
+			context:  3,
+			diff:     "",
+		},
+		{
+			name:     "duplicates",
+			expected: "0\n0\n0\n0",
+			actual:   "0\n0\n0",
+			context:  1,
+			diff:     "--- expected\n+++ actual\n@@ -3,2 +3 @@\n 0\n-0\n",
+		},
+		{
+			name:     "reordered",
+			expected: "a\nb\nc",
+			actual:   "b\na\nc",
+			context:  0,
+			diff:     "--- expected\n+++ actual\n@@ -1 +0,0 @@\n-a\n@@ -2,0 +2 @@\n+a\n",
+		},
+		{
+			name:     "separate hunks",
+			expected: "1\n2\n3\n4\n5\n6\n7\n8",
+			actual:   "x\n2\n3\n4\n5\n6\n7\ny",
+			context:  1,
+			diff:     "--- expected\n+++ actual\n@@ -1,2 +1,2 @@\n-1\n+x\n 2\n@@ -7,2 +7,2 @@\n 7\n-8\n+y\n",
+		},
+		{
+			name:     "merged hunks",
+			expected: "1\n2\n3\n4",
+			actual:   "x\n2\n3\ny",
+			context:  1,
+			diff:     "--- expected\n+++ actual\n@@ -1,4 +1,4 @@\n-1\n+x\n 2\n 3\n-4\n+y\n",
+		},
+		{
+			name:     "empty expected",
+			expected: "",
+			actual:   "a",
+			context:  3,
+			diff:     "--- expected\n+++ actual\n@@ -1 +1 @@\n-\n+a\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff := golden.Diff(strings.Split(tt.expected, "\n"), strings.Split(tt.actual, "\n"), tt.context, false)
+			if diff != tt.diff {
+				t.Errorf("expected diff:\n%v\nbut got:\n%v", tt.diff, diff)
+			}
+		})
+	}
+}
+
+// TestDiffColor checks that the changed lines are colored.
+func TestDiffColor(t *testing.T) {
+	diff := golden.Diff([]string{"a"}, []string{"b"}, 3, true)
+	for _, expected := range []string{"\x1b[31m-a\x1b[0m", "\x1b[32m+b\x1b[0m", "\x1b[36m@@ -1 +1 @@\x1b[0m"} {
+		if !strings.Contains(diff, expected) {
+			t.Errorf("expected diff to contain %q, but got %q", expected, diff)
+		}
+	}
+}
@@ -0,0 +1,222 @@
+// Copyright 2023 Meelis Utt. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Package golden provides snapshot testing of tlog log entries against golden files.
+//
+// The log output is normalized before comparing, so that values that differ between runs
+// (eg timestamps, absolute paths, durations and pointers) don't cause mismatches.
+// Golden files are created and updated by running the tests with the -golden.update flag.
+// The flag is prefixed with the package name, so that it doesn't conflict with the -update flag defined by many test packages.
+package golden
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/moledoc/tlog"
+)
+
+var update = flag.Bool("golden.update", false, "update the golden files instead of comparing against them")
+
+// Normalizer rewrites a line of log output, so that values that differ between runs can be compared.
+type Normalizer func(line string) string
+
+// regexpNormalizer creates a Normalizer that replaces the matches of the regular expression with repl, see regexp.Regexp.ReplaceAllString.
+func regexpNormalizer(expr string, repl string) Normalizer {
+	re := regexp.MustCompile(expr)
+	return func(line string) string {
+		return re.ReplaceAllString(line, repl)
+	}
+}
+
+var (
+	// Timestamps replaces timestamps, both in text format and in RFC3339 format, with <TIMESTAMP>.
+	Timestamps = regexpNormalizer(`[0-9]{4}-[0-9]{2}-[0-9]{2}[ T][0-9]{2}:[0-9]{2}:[0-9]{2}(\.[0-9]+)?(Z|[+-][0-9]{2}:[0-9]{2})?`, "<TIMESTAMP>")
+	// Paths replaces absolute paths with their base names, eg /foo/bar/baz_test.go:54 with baz_test.go:54.
+	Paths = regexpNormalizer(`(^|[^\w.\-@+~/\\])(?:[A-Za-z]:)?(?:[/\\][\w.\-@+~]+)*[/\\]([\w.\-@+~]+\.\w+)`, "$1$2")
+	// Durations replaces durations, as formatted by time.Duration.String, with <DURATION>.
+	Durations = regexpNormalizer(`\b([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+\b`, "<DURATION>")
+	// Pointers replaces hexadecimal pointer values, eg 0xc000012345, with <POINTER>.
+	Pointers = regexpNormalizer(`\b0x[0-9a-fA-F]+\b`, "<POINTER>")
+)
+
+// DefaultNormalizers are the normalizers used when no normalizers are provided.
+var DefaultNormalizers = []Normalizer{Timestamps, Paths, Durations, Pointers}
+
+// Normalize applies the normalizers, in the given order, to each line of the log output.
+func Normalize(output string, normalizers ...Normalizer) string {
+	lines := strings.Split(output, "\n")
+	for i, line := range lines {
+		for _, normalize := range normalizers {
+			line = normalize(line)
+		}
+		lines[i] = line
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Compare compares the expected and actual log output, after normalizing both with the normalizers.
+// When no normalizers are provided, DefaultNormalizers are used.
+// It returns the unified diff of the differences (see Diff), or an empty string when the outputs match.
+func Compare(expected string, actual string, normalizers ...Normalizer) string {
+	return compare(expected, actual, newConfig([]Option{WithNormalizers(normalizers...)}))
+}
+
+// compare compares the expected and actual log output according to the config.
+func compare(expected string, actual string, c *config) string {
+	expectedLines := strings.Split(Normalize(expected, c.normalizers...), "\n")
+	actualLines := strings.Split(Normalize(actual, c.normalizers...), "\n")
+	return Diff(expectedLines, actualLines, c.context, c.color)
+}
+
+// CompareEntries compares the expected and actual log entries test by test, ie grouped by tlog.Entry.Name.
+// The log entries of each test are formatted, normalized and compared on their own,
+// so that the order in which the tests ran (eg with -shuffle=on, t.Parallel() or concurrently running packages) doesn't matter.
+// It returns the unified diffs of the tests that differ, each preceded by a '=== <testname>' line,
+// or an empty string when the log entries match.
+func CompareEntries(expected []*tlog.Entry, actual []*tlog.Entry, opts ...Option) (string, error) {
+	c := newConfig(opts)
+	expectedGroups, err := groupByTest(expected, c)
+	if err != nil {
+		return "", err
+	}
+	actualGroups, err := groupByTest(actual, c)
+	if err != nil {
+		return "", err
+	}
+
+	var names []string
+	for name := range expectedGroups {
+		names = append(names, name)
+	}
+	for name := range actualGroups {
+		if _, ok := expectedGroups[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	for _, name := range names {
+		if diff := Diff(expectedGroups[name], actualGroups[name], c.context, c.color); diff != "" {
+			fmt.Fprintf(&sb, "=== %v\n%v", name, diff)
+		}
+	}
+	return sb.String(), nil
+}
+
+// groupByTest formats and normalizes the log entries and groups the resulting lines by the test name, keeping their order.
+func groupByTest(entries []*tlog.Entry, c *config) (map[string][]string, error) {
+	groups := make(map[string][]string)
+	for _, entry := range entries {
+		var sb strings.Builder
+		if _, err := c.formatter.Format(&sb, entry); err != nil {
+			return nil, fmt.Errorf("golden: failed to format log entry: %w", err)
+		}
+		text := Normalize(strings.TrimSuffix(sb.String(), "\n"), c.normalizers...)
+		groups[entry.Name] = append(groups[entry.Name], strings.Split(text, "\n")...)
+	}
+	return groups, nil
+}
+
+// Option configures Assert and CompareEntries.
+type Option func(*config)
+
+type config struct {
+	normalizers []Normalizer
+	formatter   tlog.Formatter
+	context     int
+	color       bool
+}
+
+// newConfig creates the config with the defaults and applies the options.
+// DefaultNormalizers are used, when the options leave no normalizers, so that every comparison and update normalizes the same way.
+func newConfig(opts []Option) *config {
+	c := &config{formatter: tlog.TextFormatter{}, context: DefaultContext}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if len(c.normalizers) == 0 {
+		c.normalizers = DefaultNormalizers
+	}
+	return c
+}
+
+// WithNormalizers sets the normalizers, replacing DefaultNormalizers.
+// When no normalizers are provided, DefaultNormalizers are used.
+func WithNormalizers(normalizers ...Normalizer) Option {
+	return func(c *config) {
+		c.normalizers = normalizers
+	}
+}
+
+// WithFormatter sets the formatter used to format the log entries, replacing tlog.TextFormatter.
+func WithFormatter(f tlog.Formatter) Option {
+	return func(c *config) {
+		c.formatter = f
+	}
+}
+
+// WithContext sets the number of unchanged lines shown around the changes in the reported diff, replacing DefaultContext.
+func WithContext(n int) Option {
+	return func(c *config) {
+		c.context = n
+	}
+}
+
+// WithColor sets whether the reported diff is colored with ANSI escape codes.
+func WithColor(color bool) Option {
+	return func(c *config) {
+		c.color = color
+	}
+}
+
+// Assert compares the log entries recorded in the logger against the golden file.
+// The mismatches are reported with t.Errorf.
+// When the tests are run with the -golden.update flag, the golden file is written with the normalized log entries instead.
+func Assert(t testing.TB, tl *tlog.Logger, filename string, opts ...Option) {
+	t.Helper()
+	c := newConfig(opts)
+
+	var sb strings.Builder
+	for _, entry := range tl.GetLogEntries() {
+		if _, err := c.formatter.Format(&sb, entry); err != nil {
+			t.Errorf("golden: failed to format log entry: %v", err)
+			return
+		}
+	}
+	actual := sb.String()
+
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(filename), 0750); err != nil {
+			t.Errorf("golden: failed to create directory for '%v': %v", filename, err)
+			return
+		}
+		if err := os.WriteFile(filename, []byte(Normalize(actual, c.normalizers...)), 0640); err != nil {
+			t.Errorf("golden: failed to update golden file '%v': %v", filename, err)
+		}
+		return
+	}
+
+	expected, err := os.ReadFile(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("golden: golden file '%v' doesn't exist, run the tests with -golden.update flag to create it", filename)
+		return
+	}
+	if err != nil {
+		t.Errorf("golden: failed to read golden file '%v': %v", filename, err)
+		return
+	}
+	if diff := compare(string(expected), actual, c); diff != "" {
+		t.Errorf("golden: log entries didn't match golden file '%v':\n%v", filename, diff)
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright 2023 Meelis Utt. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package golden_test
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moledoc/tlog"
+	"github.com/moledoc/tlog/golden"
+)
+
+// TestNormalizers checks that each normalizer replaces only the values that differ between runs.
+func TestNormalizers(t *testing.T) {
+	tests := []struct {
+		name       string
+		normalizer golden.Normalizer
+		line       string
+		expected   string
+	}{
+		{
+			name:       "text timestamp",
+			normalizer: golden.Timestamps,
+			line:       "2023-03-21 22:14:01.982 /foo/bar/baz_test.go:54 [TestXxx] INFO: one",
+			expected:   "<TIMESTAMP> /foo/bar/baz_test.go:54 [TestXxx] INFO: one",
+		},
+		{
+			name:       "RFC3339 timestamp",
+			normalizer: golden.Timestamps,
+			line:       `{"time":"2023-03-21T22:14:01.982123+02:00","file":"/foo/bar/baz_test.go"}`,
+			expected:   `{"time":"<TIMESTAMP>","file":"/foo/bar/baz_test.go"}`,
+		},
+		{
+			name:       "absolute paths",
+			normalizer: golden.Paths,
+			line:       `/home/utt/go/src/github.com/moledoc/tlog/tlog_test.go:119 file="/tmp/x/a.txt" C:\foo\bar.go:1 ../rel/path.log req.path=/`,
+			expected:   `tlog_test.go:119 file="a.txt" bar.go:1 ../rel/path.log req.path=/`,
+		},
+		{
+			name:       "durations",
+			normalizer: golden.Durations,
+			line:       "took 1.5ms, then 1h2m3.5s and 12µs; user=42 v2",
+			expected:   "took <DURATION>, then <DURATION> and <DURATION>; user=42 v2",
+		},
+		{
+			name:       "pointers",
+			normalizer: golden.Pointers,
+			line:       "&{0xc000012345} 0x",
+			expected:   "&{<POINTER>} 0x",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.normalizer(tt.line); got != tt.expected {
+				t.Errorf("expected %q, but got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestCompare checks that mismatching lines are reported.
+func TestCompare(t *testing.T) {
+	expected := "2023-03-21 22:14:01.982 /foo/baz_test.go:54 [TestXxx] INFO: one\n"
+	if diff := golden.Compare(expected, "2026-10-17 02:04:39.418 /bar/baz_test.go:54 [TestXxx] INFO: one\n"); diff != "" {
+		t.Errorf("expected no differences, but got:\n%v", diff)
+	}
+	if diff := golden.Compare(expected, "2026-10-17 02:04:39.418 /bar/baz_test.go:54 [TestXxx] INFO: two\n"); diff == "" {
+		t.Errorf("expected differences, but got none")
+	}
+}
+
+// TestAssert checks the logger's entries against the golden file in testdata.
+func TestAssert(t *testing.T) {
+	tl := tlog.New(t)
+	tl.Log("hello")
+	tl.Logw("connected", "took", 15*time.Millisecond, "conn", &struct{}{})
+	tl.Warnf("retrying in %v", time.Second)
+	golden.Assert(t, tl, "testdata/TestAssert.golden", golden.WithNormalizers(golden.Timestamps, golden.Paths, golden.Durations))
+}
+
+// TestAssertUpdate checks that the golden file written with -golden.update flag matches the log entries it was written from,
+// when the options leave the normalizers to the defaults.
+func TestAssertUpdate(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "TestAssertUpdate.golden")
+	tl := tlog.New(t)
+	tl.Logw("connected", "took", 15*time.Millisecond, "conn", &struct{}{})
+
+	flag.Set("golden.update", "true")
+	golden.Assert(t, tl, filename, golden.WithNormalizers())
+	flag.Set("golden.update", "false")
+	golden.Assert(t, tl, filename, golden.WithNormalizers())
+
+	written, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(string(written), "<TIMESTAMP>") {
+		t.Errorf("expected the golden file to be normalized, but got:\n%s", written)
+	}
+}
+
+// TestCompareEntries checks that log entries are compared test by test, regardless of the order of the tests.
+func TestCompareEntries(t *testing.T) {
+	ts := time.Date(2023, 3, 21, 22, 14, 1, 982000000, time.UTC)
+	entry := func(name string, msg string) *tlog.Entry {
+		return &tlog.Entry{Time: ts, Location: "/foo/bar/baz_test.go:54", Name: name, Message: msg}
+	}
+	expected := []*tlog.Entry{entry("TestA", "one"), entry("TestA", "two"), entry("TestB", "one"), entry("TestC", "one")}
+	shuffled := []*tlog.Entry{entry("TestC", "one"), entry("TestB", "one"), entry("TestA", "one"), entry("TestA", "two")}
+	if diff, err := golden.CompareEntries(expected, shuffled); err != nil || diff != "" {
+		t.Errorf("expected no differences, but got %v:\n%v", err, diff)
+	}
+
+	reordered := []*tlog.Entry{entry("TestA", "two"), entry("TestA", "one"), entry("TestB", "one"), entry("TestD", "one")}
+	diff, err := golden.CompareEntries(expected, reordered, golden.WithContext(0))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, header := range []string{"=== TestA\n", "=== TestC\n", "=== TestD\n"} {
+		if !strings.Contains(diff, header) {
+			t.Errorf("expected diff to contain %q, but got:\n%v", header, diff)
+		}
+	}
+	if strings.Contains(diff, "=== TestB\n") {
+		t.Errorf("expected diff not to contain TestB, but got:\n%v", diff)
+	}
+}
@@ -0,0 +1,219 @@
+// Copyright 2023 Meelis Utt. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package tlog
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// jsonEntry is the JSON schema of a log entry.
+type jsonEntry struct {
+	Time    time.Time `json:"time"`
+	File    string    `json:"file"`
+	Line    int       `json:"line,omitempty"`
+	Name    string    `json:"name"`
+	Level   Level     `json:"level"`
+	Message string    `json:"message"`
+	Fields  []Field   `json:"fields,omitempty"`
+}
+
+// MarshalJSON encodes the log entry as a JSON object with the following keys:
+//   - time: timestamp in the RFC3339Nano format;
+//   - file and line: location, split into filepath and row number; line is omitted, when location has no row number;
+//   - name: test's name;
+//   - level: level's name, see Level.String;
+//   - message: log message;
+//   - fields: list of fields, see Field.MarshalJSON; omitted, when there are no fields.
+func (l *Entry) MarshalJSON() ([]byte, error) {
+	file, line := splitLocation(l.Location)
+	return json.Marshal(jsonEntry{
+		Time:    l.Time,
+		File:    file,
+		Line:    line,
+		Name:    l.Name,
+		Level:   l.Level,
+		Message: l.Message,
+		Fields:  l.Fields,
+	})
+}
+
+// UnmarshalJSON decodes the log entry from a JSON object produced by Entry.MarshalJSON.
+func (l *Entry) UnmarshalJSON(b []byte) error {
+	var je jsonEntry
+	if err := json.Unmarshal(b, &je); err != nil {
+		return err
+	}
+	location := je.File
+	if je.Line > 0 {
+		location = fmt.Sprintf("%v:%v", je.File, je.Line)
+	}
+	*l = Entry{
+		Time:     je.Time,
+		Location: location,
+		Name:     je.Name,
+		Level:    je.Level,
+		Message:  je.Message,
+		Fields:   je.Fields,
+	}
+	return nil
+}
+
+// splitLocation splits the location (<filepath>:<row number>) into filepath and row number.
+// When location has no row number, the whole location is returned as the filepath.
+func splitLocation(location string) (string, int) {
+	i := strings.LastIndex(location, ":")
+	if i < 0 {
+		return location, 0
+	}
+	line, err := strconv.Atoi(location[i+1:])
+	if err != nil || line <= 0 {
+		return location, 0
+	}
+	return location[:i], line
+}
+
+// jsonField is the JSON schema of a field.
+type jsonField struct {
+	Key   string          `json:"key"`
+	Type  string          `json:"type"`
+	Value json.RawMessage `json:"value"`
+}
+
+// MarshalJSON encodes the field as a JSON object with the keys: key, type and value.
+// Type is the Go type of the value, so that the value can be decoded back to the same type.
+// Supported types are bool, string, signed and unsigned integer types, float32, float64, time.Duration, time.Time and error.
+// Errors are encoded as their messages and values of other types are encoded with type "any",
+// either as their JSON encoding or, when they can't be encoded, as their default format.
+func (f Field) MarshalJSON() ([]byte, error) {
+	var typ string
+	var value any
+	switch v := f.Value.(type) {
+	case bool, string, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		typ, value = fmt.Sprintf("%T", v), v
+	case time.Duration:
+		typ, value = "time.Duration", v.String()
+	case time.Time:
+		typ, value = "time.Time", v
+	case error:
+		typ, value = "error", v.Error()
+	default:
+		typ, value = "any", v
+		if _, err := json.Marshal(v); err != nil {
+			value = fmt.Sprint(v)
+		}
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(jsonField{Key: f.Key, Type: typ, Value: b})
+}
+
+// UnmarshalJSON decodes the field from a JSON object produced by Field.MarshalJSON.
+// Values with type "any" are decoded as generic JSON values, ie as in json.Unmarshal into an interface value.
+func (f *Field) UnmarshalJSON(b []byte) error {
+	var jf jsonField
+	if err := json.Unmarshal(b, &jf); err != nil {
+		return err
+	}
+	value, err := decodeFieldValue(jf.Type, jf.Value)
+	if err != nil {
+		return fmt.Errorf("tlog: invalid value of field %q: %w", jf.Key, err)
+	}
+	*f = Field{Key: jf.Key, Value: value}
+	return nil
+}
+
+// decodeFieldValue decodes the JSON value to the given Go type.
+func decodeFieldValue(typ string, raw json.RawMessage) (any, error) {
+	switch typ {
+	case "bool":
+		return decodeAs[bool](raw)
+	case "string":
+		return decodeAs[string](raw)
+	case "int":
+		return decodeAs[int](raw)
+	case "int8":
+		return decodeAs[int8](raw)
+	case "int16":
+		return decodeAs[int16](raw)
+	case "int32":
+		return decodeAs[int32](raw)
+	case "int64":
+		return decodeAs[int64](raw)
+	case "uint":
+		return decodeAs[uint](raw)
+	case "uint8":
+		return decodeAs[uint8](raw)
+	case "uint16":
+		return decodeAs[uint16](raw)
+	case "uint32":
+		return decodeAs[uint32](raw)
+	case "uint64":
+		return decodeAs[uint64](raw)
+	case "float32":
+		return decodeAs[float32](raw)
+	case "float64":
+		return decodeAs[float64](raw)
+	case "time.Duration":
+		s, err := decodeAs[string](raw)
+		if err != nil {
+			return nil, err
+		}
+		return time.ParseDuration(s.(string))
+	case "time.Time":
+		return decodeAs[time.Time](raw)
+	case "error":
+		s, err := decodeAs[string](raw)
+		if err != nil {
+			return nil, err
+		}
+		return errors.New(s.(string)), nil
+	case "any":
+		return decodeAs[any](raw)
+	default:
+		return nil, fmt.Errorf("unknown type %q", typ)
+	}
+}
+
+// decodeAs decodes the JSON value to a value of type T.
+func decodeAs[T any](raw json.RawMessage) (any, error) {
+	var v T
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// DecodeJSON decodes log entries from JSON Lines, as written by JSONFormatter.
+// Empty lines are skipped.
+// The returned error contains the line number of the line that couldn't be decoded.
+func DecodeJSON(r io.Reader) ([]*Entry, error) {
+	var entries []*Entry
+	br := bufio.NewReader(r)
+	for lineNr := 1; ; lineNr++ {
+		line, err := br.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return entries, err
+		}
+		if strings.TrimSpace(line) != "" {
+			entry := &Entry{}
+			if uerr := json.Unmarshal([]byte(line), entry); uerr != nil {
+				return entries, fmt.Errorf("tlog: line %v: %w", lineNr, uerr)
+			}
+			entries = append(entries, entry)
+		}
+		if err == io.EOF {
+			return entries, nil
+		}
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright 2023 Meelis Utt. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package tlog_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moledoc/tlog"
+)
+
+// TestJSONRoundTrip checks that log entries decoded from JSON Lines are the same as the encoded ones.
+func TestJSONRoundTrip(t *testing.T) {
+	entries := []*tlog.Entry{
+		formatTestEntry,
+		{
+			Time:     time.Date(2023, 3, 21, 22, 14, 1, 982123456, time.UTC),
+			Location: "<stdout>",
+			Name:     "TestXxx/sub_test",
+			Level:    tlog.LevelError + 2,
+			Message:  "\n\"one\"*os.File\n",
+		},
+		{
+			Time:     time.Date(2023, 3, 21, 22, 14, 1, 0, time.UTC),
+			Location: "/foo/bar/baz_test.go:55",
+			Name:     "TestXxx",
+			Level:    tlog.LevelDebug,
+			Message:  "typed fields",
+			Fields: []tlog.Field{
+				{Key: "bool", Value: true},
+				{Key: "int8", Value: int8(-8)},
+				{Key: "uint64", Value: uint64(1 << 63)},
+				{Key: "float32", Value: float32(1.5)},
+				{Key: "duration", Value: 1500 * time.Millisecond},
+				{Key: "time", Value: time.Date(2023, 3, 21, 0, 0, 0, 0, time.UTC)},
+				{Key: "error", Value: errors.New("failed")},
+				{Key: "any", Value: map[string]any{"one": 1.0}},
+			},
+		},
+	}
+	var sb strings.Builder
+	for _, entry := range entries {
+		if _, err := (tlog.JSONFormatter{}).Format(&sb, entry); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	decoded, err := tlog.DecodeJSON(strings.NewReader(sb.String()))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(entries, decoded) {
+		t.Errorf("decoded entries differ from the encoded ones:\n%v", sb.String())
+		for _, entry := range decoded {
+			t.Errorf("%#v", entry)
+		}
+	}
+}
+
+// TestDecodeJSONMalformed checks that the malformed line is reported with its line number.
+func TestDecodeJSONMalformed(t *testing.T) {
+	input := `{"time":"2023-03-21T22:14:01.982Z","file":"/foo/bar/baz_test.go","line":54,"name":"TestXxx","level":"INFO","message":"one"}
+
+{"time":"2023-03-21T22:14:01.982Z","level":"LOUD"}
+`
+	entries, err := tlog.DecodeJSON(strings.NewReader(input))
+	if err == nil || !strings.Contains(err.Error(), "line 3") {
+		t.Errorf("expected error on line 3, but got %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("expected 1 decoded entry, but got %v", len(entries))
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright 2023 Meelis Utt. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package tlog
+
+import (
+	"fmt"
+	"time"
+)
+
+// Limit bounds the log entries kept by a logger, so that long running tests that log a lot don't use too much memory.
+// The first First log entries are always kept and from the rest only the last ones fitting into the limits are kept.
+// When log entries are dropped, a summary log entry with the number of dropped log entries is outputted in their place,
+// leaving out the log entries that were already outputted, eg LevelError entries.
+// The zero value means no limits.
+type Limit struct {
+	First    int // number of the first log entries that are always kept, eg the test setup.
+	Last     int // maximum number of the last log entries kept after the first ones, 0 means no limit.
+	MaxBytes int // maximum size of the messages and fields of the kept log entries (excluding the first ones), 0 means no limit.
+}
+
+// LimitsTo sets the limits of the log entries kept by the logger, dropping the log entries exceeding the limits.
+// For example, Limit{Last: 1000} keeps the last 1000 log entries
+// and Limit{First: 10, Last: 1000} keeps the first 10 and the last 1000 log entries.
+func (sl *Logger) LimitsTo(limit Limit) {
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+	sl.limit = limit
+	sl.size = 0
+	if limit.MaxBytes > 0 {
+		for _, log := range sl.logs[min(limit.First, len(sl.logs)):] {
+			sl.size += entrySize(log)
+		}
+	}
+	sl.trim()
+}
+
+// entrySize returns the approximate size of the log entry's message and fields in bytes.
+// The pending message is formatted, so the byte budget makes logging more expensive.
+func entrySize(entry *Entry) int {
+	if entry.pending {
+		entry.formatMessage()
+	}
+	size := len(entry.Message)
+	for _, f := range entry.Fields {
+		size += len(f.Key) + len(fmt.Sprint(f.Value))
+	}
+	return size
+}
+
+// trim drops the oldest log entries after the first ones, until the log entries fit into the logger's limits.
+// The newest log entry is never dropped.
+// The caller must hold the logger's lock.
+func (sl *Logger) trim() {
+	first := sl.limit.First
+	for len(sl.logs) > first+1 &&
+		(sl.limit.Last > 0 && len(sl.logs)-first > sl.limit.Last || sl.limit.MaxBytes > 0 && sl.size > sl.limit.MaxBytes) {
+		if sl.limit.MaxBytes > 0 {
+			sl.size -= entrySize(sl.logs[first])
+		}
+		if sl.logs[first].printed {
+			sl.reported++
+		}
+		// NOTE: instead of moving the last entries, move the first entries over the dropped one,
+		// so that dropping costs O(First), which is meant to be small.
+		copy(sl.logs[1:first+1], sl.logs[:first])
+		sl.logs[0] = nil
+		sl.logs = sl.logs[1:]
+		sl.dropped++
+	}
+}
+
+// markIndex returns the current index of the mark in the logger's log entries,
+// where the mark is the number of log entries made (including the dropped ones) at some point, eg when the child logger was created.
+// The caller must hold the logger's lock.
+func (sl *Logger) markIndex(mark int) int {
+	i := max(mark-sl.dropped, min(mark, sl.limit.First))
+	return min(i, len(sl.logs))
+}
+
+// withDropped returns the log entries with the summary log entry of the dropped log entries in their place,
+// when log entries are dropped since the last summary was outputted (see Logger.dump).
+// The caller must hold the logger's lock.
+func (sl *Logger) withDropped(logs []*Entry) []*Entry {
+	if sl.dropped == sl.reported {
+		return logs
+	}
+	first := min(sl.limit.First, len(logs))
+	summary := &Entry{
+		Time:     time.Now(),
+		Location: "<dropped>",
+		Name:     sl.t.Name(),
+		Level:    LevelWarn,
+		Message:  fmt.Sprintf("... %v log entries dropped ...", sl.dropped-sl.reported),
+	}
+	if first < len(logs) {
+		summary.Time = logs[first].Time
+	}
+	withDropped := make([]*Entry, 0, len(logs)+1)
+	withDropped = append(withDropped, logs[:first]...)
+	withDropped = append(withDropped, summary)
+	return append(withDropped, logs[first:]...)
+}
@@ -0,0 +1,183 @@
+// Copyright 2023 Meelis Utt. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package tlog
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync"
+)
+
+// LocationFormat is the format of the log entry locations resolved from the call stack, see Logger.FormatsLocations.
+// Locations that are not resolved from the call stack, eg <stdout> of the lines captured by Logger.CaptureStdio, are not affected.
+type LocationFormat int
+
+const (
+	// LocationFull formats the location as the absolute file path and the line number, eg /home/user/src/tlog/tlog_test.go:54, this is the default.
+	LocationFull LocationFormat = iota
+	// LocationModule formats the location as the file path relative to the module root and the line number, eg nested_pkg/nested_pkg_test.go:54,
+	// so that the locations are the same on every machine.
+	LocationModule
+	// LocationBase formats the location as the file name and the line number, eg nested_pkg_test.go:54.
+	LocationBase
+	// LocationFunc formats the location as the absolute file path and the line number followed by the function name,
+	// eg /home/user/src/tlog/tlog_test.go:54 github.com/moledoc/tlog_test.TestLogs.
+	LocationFunc
+)
+
+// String returns the name of the location format, eg module.
+func (f LocationFormat) String() string {
+	switch f {
+	case LocationFull:
+		return "full"
+	case LocationModule:
+		return "module"
+	case LocationBase:
+		return "base"
+	case LocationFunc:
+		return "func"
+	default:
+		return fmt.Sprintf("LocationFormat(%d)", int(f))
+	}
+}
+
+// callerDepth is the maximum number of callers recorded for resolving the location of a log entry.
+const callerDepth = 16
+
+// packagePath is the import path of the tlog package.
+var packagePath = func() string {
+	pc, _, _, _ := runtime.Caller(0)
+	return funcPackage(runtime.FuncForPC(pc).Name())
+}()
+
+// modulePaths contains the paths of the modules in the build, used to make the locations relative to the module root.
+var modulePaths = func() []string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return nil
+	}
+	paths := []string{info.Main.Path}
+	for _, dep := range info.Deps {
+		paths = append(paths, dep.Path)
+	}
+	return paths
+}()
+
+// funcPackage returns the import path of the package of the fully qualified function name,
+// eg github.com/moledoc/tlog for github.com/moledoc/tlog.(*Logger).Logf.
+func funcPackage(name string) string {
+	if i := strings.Index(name, "["); i >= 0 {
+		name = name[:i] // NOTE: type parameters of generic functions can contain slashes and dots
+	}
+	slash := strings.LastIndex(name, "/")
+	if i := strings.Index(name[slash+1:], "."); i >= 0 {
+		return name[:slash+1+i]
+	}
+	return name
+}
+
+// internalFrame reports whether the frame belongs to the tlog package source files, excluding its tests.
+func internalFrame(frame runtime.Frame) bool {
+	return funcPackage(frame.Function) == packagePath && !strings.HasSuffix(frame.File, "_test.go")
+}
+
+// callers returns the program counters of the callers of the function calling callers.
+func callers() [callerDepth]uintptr {
+	var pcs [callerDepth]uintptr
+	runtime.Callers(3, pcs[:])
+	return pcs
+}
+
+// framesLocation returns the location of the first frame outside of the tlog package source files and the helper functions,
+// formatted according to the format.
+// skip is the number of such frames skipped after that frame, see Logger.CallerSkip.
+// When the frames run out, the location of the last frame is returned.
+func framesLocation(pcs []uintptr, skip int, format LocationFormat, helpers *helperSet) string {
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		if internalFrame(frame) || helpers.contains(frame.Function) {
+			if more {
+				continue
+			}
+		} else if skip > 0 && more {
+			skip--
+			continue
+		}
+		return formatLocation(frame, format)
+	}
+}
+
+// formatLocation formats the location of the frame according to the format.
+func formatLocation(frame runtime.Frame, format LocationFormat) string {
+	switch format {
+	case LocationModule:
+		return fmt.Sprintf("%v:%v", moduleRelative(frame), frame.Line)
+	case LocationBase:
+		return fmt.Sprintf("%v:%v", filepath.Base(frame.File), frame.Line)
+	case LocationFunc:
+		return fmt.Sprintf("%v:%v %v", frame.File, frame.Line, frame.Function)
+	default:
+		return fmt.Sprintf("%v:%v", frame.File, frame.Line)
+	}
+}
+
+// moduleRelative returns the frame's file path relative to the root of the module containing the frame's package.
+// When the module is not known, the absolute file path is returned.
+func moduleRelative(frame runtime.Frame) string {
+	pkg := strings.TrimSuffix(funcPackage(frame.Function), "_test")
+	var module string
+	for _, m := range modulePaths {
+		if (pkg == m || strings.HasPrefix(pkg, m+"/")) && len(m) > len(module) {
+			module = m
+		}
+	}
+	if module == "" {
+		return frame.File
+	}
+	return path.Join(strings.TrimPrefix(strings.TrimPrefix(pkg, module), "/"), filepath.Base(frame.File))
+}
+
+// helperSet contains the functions marked as helpers by Logger.Helper.
+// It's shared between the loggers of a test and its subtests.
+type helperSet struct {
+	mu    sync.RWMutex
+	pcs   map[uintptr]struct{} // program counters of the Logger.Helper calls, to avoid resolving the function names repeatedly.
+	names map[string]struct{}  // fully qualified names of the helper functions.
+}
+
+// add marks the function containing the program counter as a helper function.
+func (h *helperSet) add(pc uintptr) {
+	h.mu.RLock()
+	_, ok := h.pcs[pc]
+	h.mu.RUnlock()
+	if ok {
+		return
+	}
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.pcs == nil {
+		h.pcs = map[uintptr]struct{}{}
+		h.names = map[string]struct{}{}
+	}
+	h.pcs[pc] = struct{}{}
+	h.names[frame.Function] = struct{}{}
+}
+
+// contains reports whether the function is marked as a helper function.
+func (h *helperSet) contains(function string) bool {
+	if h == nil {
+		return false
+	}
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	_, ok := h.names[function]
+	return ok
+}
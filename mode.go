@@ -0,0 +1,113 @@
+// Copyright 2023 Meelis Utt. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package tlog
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"testing"
+)
+
+// Mode is the retention policy of a logger, ie it determines when the log entries are outputted at the end of the test.
+type Mode int
+
+const (
+	// ModeOnFail outputs the log entries when the test fails or panics, this is the default mode.
+	// LevelWarn entries are outputted as well, when the test passes and -test.v flag is set, see Level.
+	ModeOnFail Mode = iota
+	// ModeAlways outputs the log entries regardless of the test result, eg to debug passing tests locally.
+	ModeAlways
+	// ModeNever never outputs the log entries, including the LevelError entries, eg to silence noisy test suites in CI.
+	ModeNever
+	// ModeOnSkip outputs the log entries when the test fails, panics or is skipped.
+	ModeOnSkip
+)
+
+// modeNames contains the names of the modes, as used by the -tlog.mode flag and the TLOG_MODE environment variable.
+var modeNames = map[Mode]string{
+	ModeOnFail: "on-fail",
+	ModeAlways: "always",
+	ModeNever:  "never",
+	ModeOnSkip: "on-skip",
+}
+
+// String returns the name of the mode, eg on-fail.
+func (m Mode) String() string {
+	if name, ok := modeNames[m]; ok {
+		return name
+	}
+	return fmt.Sprintf("Mode(%d)", int(m))
+}
+
+// MarshalText encodes the mode as its name, see Mode.String.
+func (m Mode) MarshalText() ([]byte, error) {
+	if _, ok := modeNames[m]; !ok {
+		return nil, fmt.Errorf("tlog: unknown mode %d", int(m))
+	}
+	return []byte(m.String()), nil
+}
+
+// UnmarshalText decodes the mode from its name, as produced by Mode.String.
+func (m *Mode) UnmarshalText(text []byte) error {
+	for mode, name := range modeNames {
+		if name == string(text) {
+			*m = mode
+			return nil
+		}
+	}
+	return fmt.Errorf("tlog: unknown mode name %q", text)
+}
+
+// defaultMode is the mode of the loggers without their own mode, see Logger.SetMode.
+// It's set by the -tlog.mode flag, which defaults to the TLOG_MODE environment variable or ModeOnFail.
+var defaultMode = func() Mode {
+	mode := ModeOnFail
+	if env, ok := os.LookupEnv("TLOG_MODE"); ok {
+		if err := mode.UnmarshalText([]byte(env)); err != nil {
+			fmt.Fprintf(os.Stderr, "tlog: ignoring TLOG_MODE: %v\n", err)
+		}
+	}
+	return mode
+}()
+
+func init() {
+	flag.TextVar(&defaultMode, "tlog.mode", defaultMode, "when to output the log entries: on-fail, always, never or on-skip (defaults to TLOG_MODE environment variable)")
+}
+
+// SetMode sets the logger's retention policy, overriding the one set by the -tlog.mode flag or the TLOG_MODE environment variable.
+func (sl *Logger) SetMode(mode Mode) {
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+	sl.mode = &mode
+}
+
+// currentMode returns the logger's mode, or the default mode when the logger doesn't have its own mode.
+// The caller must hold the logger's lock.
+func (sl *Logger) currentMode() Mode {
+	if sl.mode != nil {
+		return *sl.mode
+	}
+	return defaultMode
+}
+
+// retained reports whether log entries should be outputted at the end of the test according to the logger's mode,
+// and the levels of the log entries to output, all levels when no levels are returned.
+// failed reports whether the test failed or panicked.
+func (sl *Logger) retained(failed bool) (bool, []Level) {
+	sl.mu.RLock()
+	mode := sl.currentMode()
+	sl.mu.RUnlock()
+	switch {
+	case mode == ModeNever:
+		return false, nil
+	case mode == ModeAlways || failed || mode == ModeOnSkip && sl.t.Skipped():
+		return true, nil
+	case testing.Verbose():
+		return true, []Level{LevelWarn}
+	default:
+		return false, nil
+	}
+}
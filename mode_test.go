@@ -0,0 +1,50 @@
+// Copyright 2023 Meelis Utt. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package tlog_test
+
+import (
+	"testing"
+
+	"github.com/moledoc/tlog"
+)
+
+// TestModeText should unmarshal the text of each mode back to the mode and fail for unknown modes and mode names.
+func TestModeText(t *testing.T) {
+	tcs := []struct {
+		name string
+		mode tlog.Mode
+	}{
+		{name: "on-fail", mode: tlog.ModeOnFail},
+		{name: "always", mode: tlog.ModeAlways},
+		{name: "never", mode: tlog.ModeNever},
+		{name: "on-skip", mode: tlog.ModeOnSkip},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			text, err := tc.mode.MarshalText()
+			if err != nil {
+				t.Fatalf("failed to marshal mode %v: %v", tc.mode, err)
+			}
+			if string(text) != tc.name {
+				t.Errorf("expected %q, got %q", tc.name, text)
+			}
+			var actual tlog.Mode
+			if err := actual.UnmarshalText(text); err != nil {
+				t.Fatalf("failed to unmarshal mode %q: %v", text, err)
+			}
+			if actual != tc.mode {
+				t.Errorf("expected %v, got %v", tc.mode, actual)
+			}
+		})
+	}
+
+	var mode tlog.Mode
+	if err := mode.UnmarshalText([]byte("sometimes")); err == nil {
+		t.Errorf("expected error for unknown mode name, got %v", mode)
+	}
+	if _, err := tlog.Mode(42).MarshalText(); err == nil {
+		t.Errorf("expected error for unknown mode")
+	}
+}
@@ -3,15 +3,17 @@
 // license that can be found in the LICENSE file.
 
 // nestedpkg shows that tlog provides proper relative paths for nested packages.
-// The results are stored in the same files as tlog_test to be able to run comparison program on them.
+// The results are stored in the same way as in tlog_test to be able to run comparison program on them,
+// but in the package's own test_results directory, so that the packages can be tested concurrently.
 package nestedpkg
 
 import (
+	"errors"
 	"flag"
 	"fmt"
+	"io/fs"
 	"os"
 	"testing"
-	"time"
 
 	"github.com/moledoc/tlog"
 )
@@ -22,17 +24,45 @@ func add(a int, b int) int {
 
 var (
 	record                      bool
-	testResultsDir              string = "../test_results"
+	testResultsDir              string = "test_results"
 	expectedTestResultsFilename string = testResultsDir + "/expected.log"
 	actualTestResultsFilename   string = testResultsDir + "/actual.log"
 )
 
+func truncateFile(filename string) {
+	f, err := os.OpenFile(filename, os.O_TRUNC|os.O_CREATE, 0750)
+	if err != nil {
+		fmt.Printf("[WARNING]: Failed to truncate file '%v': %v\n", filename, err)
+		f, err = os.OpenFile(filename, os.O_CREATE, 0750)
+		if err != nil {
+			fmt.Printf("[FATAL]: Failed to open file '%v': %v\n", filename, err)
+			os.Exit(1)
+		}
+	}
+	f.Close()
+}
+
 func TestMain(m *testing.M) {
-	time.Sleep(10 * time.Second) // FIXME: quick and naive fix to make sure nested pkg tests ran after the main ones.
 	// NOTE: Parse new flags
 	flag.BoolVar(&record, "record", false, "Indicates whether to record new test results or not")
 	flag.Parse()
 
+	// NOTE: check if test_results dir exist
+	if _, err := os.Stat(testResultsDir); errors.Is(err, fs.ErrNotExist) {
+		if err := os.Mkdir(testResultsDir, 0750); err != nil {
+			fmt.Printf("Failed to create '%v' directory: %v\n", testResultsDir, err)
+			os.Exit(1)
+		}
+	}
+
+	// NOTE: empty actual test result file
+	truncateFile(actualTestResultsFilename)
+
+	// NOTE: empty expected test result file, if we are recording
+	if record {
+		truncateFile(expectedTestResultsFilename)
+	}
+
 	os.Exit(m.Run())
 }
 
@@ -48,7 +78,7 @@ func setupTestcase(t *testing.T) (*tlog.Logger, *os.File) {
 	t.Helper()
 	filename := getOutputFilename()
 
-	f, err := os.OpenFile(filename, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0750)
+	f, err := os.OpenFile(filename, os.O_APPEND|os.O_WRONLY, 0750)
 	if err != nil {
 		t.Fatalf("unable to open file '%v'\n", filename)
 	}
@@ -65,3 +95,10 @@ func TestNestedFilepath(t *testing.T) {
 	tl.Logf("Add(%v,%v)=%v", a, b, add(a, b))
 	t.FailNow()
 }
+
+func TestNestedModuleLocation(t *testing.T) {
+	tl, _ := setupTestcase(t)
+	tl.FormatsLocations(tlog.LocationModule)
+	tl.Logf("Add(%v,%v)=%v", 1, 2, add(1, 2))
+	t.FailNow()
+}
@@ -0,0 +1,141 @@
+// Copyright 2023 Meelis Utt. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package tlog
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// textTimeLayout is the timestamp layout used by TextFormatter.
+const textTimeLayout = "2006-01-02 15:04:05.000"
+
+// textHeaderRe matches the beginning of a log entry written by TextFormatter, ie the optional indentation and the timestamp.
+var textHeaderRe = regexp.MustCompile(`^((?:` + indentUnit + `)*)[0-9]{4}-[0-9]{2}-[0-9]{2} [0-9]{2}:[0-9]{2}:[0-9]{2}\.[0-9]{3} `)
+
+// ParseEntries parses log entries written in the text format, ie as produced by Entry.String and TextFormatter.
+//
+// Each line starting with a timestamp begins a new log entry, other lines are continuation lines of the previous entry's message.
+// This means that multi-line messages are parsed back as they were logged,
+// unless a continuation line itself starts with a timestamp.
+// Log entries indented by subtest depth (see Logger.Child) are parsed as well, the indentation is removed from all their lines.
+// The returned error contains the line number of the malformed line, eg a line before the first log entry.
+//
+// The text format is lossy: the timestamp has millisecond precision in UTC and the field values are parsed as strings.
+func ParseEntries(r io.Reader) ([]*Entry, error) {
+	var entries []*Entry
+	var msgLines []string
+	var indentation string
+	flush := func() {
+		if len(entries) > 0 {
+			entries[len(entries)-1].Message = strings.Join(msgLines, "\n")
+		}
+	}
+	br := bufio.NewReader(r)
+	for lineNr := 1; ; lineNr++ {
+		line, err := br.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return entries, err
+		}
+		if err == io.EOF && line == "" {
+			break
+		}
+		line = strings.TrimSuffix(line, "\n")
+		switch match := textHeaderRe.FindStringSubmatch(line); {
+		case match != nil:
+			flush()
+			indentation = match[1]
+			entry, msg, perr := parseHeader(line[len(indentation):])
+			if perr != nil {
+				return entries, fmt.Errorf("tlog: line %v: %w", lineNr, perr)
+			}
+			entries = append(entries, entry)
+			msgLines = []string{msg}
+		case len(entries) == 0:
+			return entries, fmt.Errorf("tlog: line %v: expected log entry to start with a timestamp", lineNr)
+		default:
+			msgLines = append(msgLines, strings.TrimPrefix(line, indentation))
+		}
+		if err == io.EOF {
+			break
+		}
+	}
+	flush()
+	return entries, nil
+}
+
+// parseHeader parses the first line of a log entry: <timestamp> <location> [<testname>] <level>[ <key>=<value>...]: <message>
+// It returns the log entry without the message and the part of the message on the first line.
+func parseHeader(line string) (*Entry, string, error) {
+	ts, err := time.Parse(textTimeLayout, line[:len(textTimeLayout)])
+	if err != nil {
+		return nil, "", err
+	}
+	entry := &Entry{Time: ts}
+	rest := line[len(textTimeLayout)+1:]
+
+	i := strings.Index(rest, " [")
+	if i <= 0 {
+		return nil, "", errors.New("missing location or test name")
+	}
+	entry.Location, rest = rest[:i], rest[i+2:]
+
+	i = strings.Index(rest, "] ")
+	if i < 0 {
+		return nil, "", errors.New("missing end of test name")
+	}
+	entry.Name, rest = rest[:i], rest[i+2:]
+
+	i = strings.IndexAny(rest, " :")
+	if i < 0 {
+		return nil, "", errors.New("missing level")
+	}
+	if err := entry.Level.UnmarshalText([]byte(rest[:i])); err != nil {
+		return nil, "", err
+	}
+	rest = rest[i:]
+
+	for strings.HasPrefix(rest, " ") {
+		var f Field
+		f, rest, err = parseField(rest[1:])
+		if err != nil {
+			return nil, "", err
+		}
+		entry.Fields = append(entry.Fields, f)
+	}
+	if !strings.HasPrefix(rest, ": ") {
+		return nil, "", errors.New("missing ': ' before the message")
+	}
+	return entry, rest[2:], nil
+}
+
+// parseField parses a field in the k=v form, as produced by Field.String.
+// It returns the field and the rest of the string.
+func parseField(s string) (Field, string, error) {
+	i := strings.Index(s, "=")
+	if i <= 0 {
+		return Field{}, "", fmt.Errorf("invalid field %q", s)
+	}
+	key, rest := s[:i], s[i+1:]
+	if strings.HasPrefix(rest, `"`) {
+		quoted, err := strconv.QuotedPrefix(rest)
+		if err != nil {
+			return Field{}, "", fmt.Errorf("invalid value of field %q: %w", key, err)
+		}
+		value, _ := strconv.Unquote(quoted)
+		return Field{Key: key, Value: value}, rest[len(quoted):], nil
+	}
+	i = strings.IndexAny(rest, " :")
+	if i < 0 {
+		return Field{}, "", fmt.Errorf("invalid value of field %q", key)
+	}
+	return Field{Key: key, Value: rest[:i]}, rest[i:], nil
+}
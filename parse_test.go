@@ -0,0 +1,102 @@
+// Copyright 2023 Meelis Utt. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package tlog_test
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moledoc/tlog"
+)
+
+// TestParseEntriesRoundTrip checks that log entries parsed from the text format are the same as the formatted ones.
+func TestParseEntriesRoundTrip(t *testing.T) {
+	ts := time.Date(2023, 3, 21, 22, 14, 1, 982000000, time.UTC)
+	entries := []*tlog.Entry{
+		{Time: ts, Location: "/foo/bar/baz_test.go:54", Name: "TestXxx", Level: tlog.LevelInfo, Message: "one"},
+		{Time: ts, Location: "/foo/bar/baz_test.go:55", Name: "TestXxx", Level: tlog.LevelInfo, Message: "\tone\n"},
+		{Time: ts, Location: "/foo/bar/baz_test.go:56", Name: "TestXxx", Level: tlog.LevelDebug, Message: "\n\"one\"*os.File"},
+		{Time: ts, Location: "/foo/bar/baz_test.go:57", Name: "TestXxx/sub", Level: tlog.LevelWarn + 1, Message: ""},
+		{
+			Time: ts, Location: "/foo/bar/baz_test.go:58", Name: "TestXxx", Level: tlog.LevelError, Message: "a=b: c",
+			Fields: []tlog.Field{{Key: "user", Value: "42"}, {Key: "op", Value: "get user: 42"}, {Key: "empty", Value: ""}},
+		},
+	}
+	var sb strings.Builder
+	for _, entry := range entries {
+		sb.WriteString(entry.String())
+	}
+	parsed, err := tlog.ParseEntries(strings.NewReader(sb.String()))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(entries, parsed) {
+		t.Errorf("parsed entries differ from the formatted ones:\n%v", sb.String())
+		for _, entry := range parsed {
+			t.Errorf("%#v", entry)
+		}
+	}
+}
+
+// TestParseEntriesIndented checks that the indentation of subtest log entries is removed from all their lines.
+func TestParseEntriesIndented(t *testing.T) {
+	input := "2023-03-21 22:14:01.982 /foo/bar/baz_test.go:54 [TestXxx] INFO: one\n" +
+		"    2023-03-21 22:14:01.982 /foo/bar/baz_test.go:55 [TestXxx/sub] INFO: multiline\n" +
+		"      message\n" +
+		"        2023-03-21 22:14:01.982 /foo/bar/baz_test.go:56 [TestXxx/sub/deep] INFO: deep\n"
+	entries, err := tlog.ParseEntries(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got []string
+	for _, entry := range entries {
+		got = append(got, entry.Name+": "+entry.Message)
+	}
+	expected := []string{"TestXxx: one", "TestXxx/sub: multiline\n  message", "TestXxx/sub/deep: deep"}
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("expected %q, but got %q", expected, got)
+	}
+}
+
+// TestParseEntriesExpectedResults checks that the recorded test results can be parsed.
+func TestParseEntriesExpectedResults(t *testing.T) {
+	f, err := os.Open(expectedTestResultsFilename)
+	if err != nil {
+		t.Fatalf("unable to open file '%v'\n", expectedTestResultsFilename)
+	}
+	defer f.Close()
+	entries, err := tlog.ParseEntries(f)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(entries) == 0 {
+		t.Errorf("expected log entries in '%v'", expectedTestResultsFilename)
+	}
+}
+
+// TestParseEntriesMalformed checks that malformed lines are reported with their line numbers.
+func TestParseEntriesMalformed(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		line  string
+	}{
+		{name: "text before entries", input: "hello\n", line: "line 1:"},
+		{name: "missing test name", input: "2023-03-21 22:14:01.982 /foo/bar/baz_test.go:54 one\n", line: "line 1:"},
+		{name: "unknown level", input: "2023-03-21 22:14:01.982 /foo/bar/baz_test.go:54 [TestXxx] INFO: one\ntwo\n2023-03-21 22:14:01.982 /foo/bar/baz_test.go:54 [TestXxx] LOUD: one\n", line: "line 3:"},
+		{name: "unterminated field", input: "2023-03-21 22:14:01.982 /foo/bar/baz_test.go:54 [TestXxx] INFO k=\"v: one\n", line: "line 1:"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tlog.ParseEntries(strings.NewReader(tt.input))
+			if err == nil || !strings.Contains(err.Error(), tt.line) {
+				t.Errorf("expected error on %v, but got %v", tt.line, err)
+			}
+		})
+	}
+}
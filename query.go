@@ -0,0 +1,148 @@
+// Copyright 2023 Meelis Utt. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package tlog
+
+import (
+	"fmt"
+	"iter"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Query selects log entries, see Logger.Query and Logger.Entries.
+// A log entry matches the query when it matches all the set conditions, the zero value matches all log entries.
+type Query struct {
+	From     time.Time      // log entries made at or after this time, zero means no lower bound.
+	To       time.Time      // log entries made before this time, zero means no upper bound.
+	Message  *regexp.Regexp // log entries whose message matches the regular expression.
+	Location string         // log entries whose location contains the string, eg "handler.go" or "handler.go:54".
+	Levels   []Level        // log entries with one of the levels, a named level includes the levels up to the next named level (see Level).
+	Fields   []Field        // log entries having all the fields, the field values are compared by their default format (fmt.Sprint).
+}
+
+// Match reports whether the log entry matches the query.
+func (q Query) Match(entry *Entry) bool {
+	for _, cond := range q.conditions() {
+		if !cond(entry) {
+			return false
+		}
+	}
+	return true
+}
+
+// score returns the number of the query's conditions, that the log entry matches.
+// It's used to find the log entries nearest to matching the query, see Logger.ExpectEntry.
+func (q Query) score(entry *Entry) int {
+	var score int
+	for _, cond := range q.conditions() {
+		if cond(entry) {
+			score++
+		}
+	}
+	return score
+}
+
+// conditions returns the conditions set in the query, each field is a separate condition.
+func (q Query) conditions() []func(*Entry) bool {
+	var conds []func(*Entry) bool
+	if !q.From.IsZero() {
+		conds = append(conds, func(entry *Entry) bool { return !entry.Time.Before(q.From) })
+	}
+	if !q.To.IsZero() {
+		conds = append(conds, func(entry *Entry) bool { return entry.Time.Before(q.To) })
+	}
+	if q.Message != nil {
+		conds = append(conds, func(entry *Entry) bool { return q.Message.MatchString(entry.Message) })
+	}
+	if q.Location != "" {
+		conds = append(conds, func(entry *Entry) bool { return strings.Contains(entry.Location, q.Location) })
+	}
+	if len(q.Levels) > 0 {
+		conds = append(conds, func(entry *Entry) bool { return slices.ContainsFunc(q.Levels, entry.Level.matches) })
+	}
+	for _, f := range q.Fields {
+		conds = append(conds, func(entry *Entry) bool { return hasField(entry.Fields, f) })
+	}
+	return conds
+}
+
+// String returns the conditions set in the query, eg Query{Message: "^retry", Levels: [WARN]}.
+func (q Query) String() string {
+	var conds []string
+	if !q.From.IsZero() {
+		conds = append(conds, fmt.Sprintf("From: %v", q.From.Format(time.RFC3339Nano)))
+	}
+	if !q.To.IsZero() {
+		conds = append(conds, fmt.Sprintf("To: %v", q.To.Format(time.RFC3339Nano)))
+	}
+	if q.Message != nil {
+		conds = append(conds, fmt.Sprintf("Message: %q", q.Message))
+	}
+	if q.Location != "" {
+		conds = append(conds, fmt.Sprintf("Location: %q", q.Location))
+	}
+	if len(q.Levels) > 0 {
+		conds = append(conds, fmt.Sprintf("Levels: %v", q.Levels))
+	}
+	if len(q.Fields) > 0 {
+		conds = append(conds, fmt.Sprintf("Fields: %v", q.Fields))
+	}
+	return "Query{" + strings.Join(conds, ", ") + "}"
+}
+
+// hasField reports whether the fields contain a field with the same key and value as f, see Query.Fields.
+func hasField(fields []Field, f Field) bool {
+	for _, ef := range fields {
+		if ef.Key == f.Key && fmt.Sprint(ef.Value) == fmt.Sprint(f.Value) {
+			return true
+		}
+	}
+	return false
+}
+
+// snapshot returns copies of the logger's log entries, so that they can be read while the logger is used.
+func (sl *Logger) snapshot() []*Entry {
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+	entries := make([]*Entry, len(sl.logs))
+	for i, log := range sl.logs {
+		sl.resolve(log)
+		entry := *log
+		entry.Fields = append([]Field(nil), log.Fields...)
+		entries[i] = &entry
+	}
+	return entries
+}
+
+// Query returns copies of the log entries recorded in the logger, that match the query.
+func (sl *Logger) Query(q Query) []*Entry {
+	var entries []*Entry
+	for entry := range sl.Entries(q) {
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// Entries returns an iterator over copies of the log entries recorded in the logger, that match the query.
+// The log entries are copied when the iteration starts, so the logger can be used during the iteration.
+//
+//	var prev time.Time
+//	for entry := range tl.Entries(tlog.Query{Location: "handler.go"}) {
+//		if !prev.IsZero() {
+//			tl.Println("time since previous entry:", entry.Time.Sub(prev))
+//		}
+//		prev = entry.Time
+//	}
+func (sl *Logger) Entries(q Query) iter.Seq[*Entry] {
+	return func(yield func(*Entry) bool) {
+		for _, entry := range sl.snapshot() {
+			if q.Match(entry) && !yield(entry) {
+				return
+			}
+		}
+	}
+}
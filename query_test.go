@@ -0,0 +1,79 @@
+// Copyright 2023 Meelis Utt. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package tlog_test
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/moledoc/tlog"
+)
+
+// TestQueryMatch should match the log entry only when all the conditions of the query hold.
+func TestQueryMatch(t *testing.T) {
+	entry := &tlog.Entry{
+		Time:     time.Date(2023, 3, 21, 22, 14, 1, 0, time.UTC),
+		Location: "/foo/bar/handler.go:54",
+		Name:     "TestXxx",
+		Level:    tlog.LevelWarn,
+		Message:  "request failed",
+		Fields:   []tlog.Field{{Key: "status", Value: 500}, {Key: "path", Value: "/"}},
+	}
+	tcs := []struct {
+		name     string
+		query    tlog.Query
+		expected bool
+	}{
+		{name: "zero", query: tlog.Query{}, expected: true},
+		{name: "from", query: tlog.Query{From: entry.Time}, expected: true},
+		{name: "from after", query: tlog.Query{From: entry.Time.Add(time.Millisecond)}, expected: false},
+		{name: "to", query: tlog.Query{To: entry.Time.Add(time.Millisecond)}, expected: true},
+		{name: "to exclusive", query: tlog.Query{To: entry.Time}, expected: false},
+		{name: "message", query: tlog.Query{Message: regexp.MustCompile(`^request`)}, expected: true},
+		{name: "message mismatch", query: tlog.Query{Message: regexp.MustCompile(`^response`)}, expected: false},
+		{name: "location", query: tlog.Query{Location: "handler.go:54"}, expected: true},
+		{name: "location mismatch", query: tlog.Query{Location: "server.go"}, expected: false},
+		{name: "levels", query: tlog.Query{Levels: []tlog.Level{tlog.LevelError, tlog.LevelWarn}}, expected: true},
+		{name: "levels mismatch", query: tlog.Query{Levels: []tlog.Level{tlog.LevelInfo}}, expected: false},
+		{name: "fields", query: tlog.Query{Fields: []tlog.Field{{Key: "path", Value: "/"}, {Key: "status", Value: "500"}}}, expected: true},
+		{name: "fields mismatch", query: tlog.Query{Fields: []tlog.Field{{Key: "status", Value: 200}}}, expected: false},
+		{name: "all", query: tlog.Query{Location: "handler.go", Levels: []tlog.Level{tlog.LevelWarn}, Fields: []tlog.Field{{Key: "status", Value: 500}}}, expected: true},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := tc.query.Match(entry); actual != tc.expected {
+				t.Errorf("expected %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+// TestQueryMatchLevels should match the log entry, when its level is one of the query levels
+// or between a named query level and the next named level.
+func TestQueryMatchLevels(t *testing.T) {
+	tcs := []struct {
+		name     string
+		level    tlog.Level
+		query    tlog.Query
+		expected bool
+	}{
+		{name: "named", level: tlog.LevelWarn, query: tlog.Query{Levels: []tlog.Level{tlog.LevelWarn}}, expected: true},
+		{name: "offset selected by named", level: tlog.LevelWarn + 2, query: tlog.Query{Levels: []tlog.Level{tlog.LevelWarn}}, expected: true},
+		{name: "offset selected by itself", level: tlog.LevelWarn + 2, query: tlog.Query{Levels: []tlog.Level{tlog.LevelWarn + 2}}, expected: true},
+		{name: "named not selected by offset", level: tlog.LevelWarn, query: tlog.Query{Levels: []tlog.Level{tlog.LevelWarn + 2}}, expected: false},
+		{name: "next named", level: tlog.LevelError, query: tlog.Query{Levels: []tlog.Level{tlog.LevelWarn}}, expected: false},
+		{name: "below debug", level: tlog.LevelDebug - 2, query: tlog.Query{Levels: []tlog.Level{tlog.LevelDebug}}, expected: true},
+		{name: "above error", level: tlog.LevelError + 4, query: tlog.Query{Levels: []tlog.Level{tlog.LevelError}}, expected: true},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			entry := &tlog.Entry{Level: tc.level}
+			if actual := tc.query.Match(entry); actual != tc.expected {
+				t.Errorf("expected %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+}
@@ -5,88 +5,74 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
-	"regexp"
+	"path/filepath"
+
+	"github.com/moledoc/tlog"
+	"github.com/moledoc/tlog/golden"
 )
 
 var (
-	testResultsDir              string = "test_results"
-	expectedTestResultsFilename string = testResultsDir + "/expected.log"
-	actualTestResultsFilename   string = testResultsDir + "/actual.log"
+	// NOTE: each tested package stores its results in its own test_results directory
+	testResultsDirPatterns      []string = []string{"test_results", "*/test_results"}
+	expectedTestResultsFilename string   = "expected.log"
+	actualTestResultsFilename   string   = "actual.log"
 )
 
-func main() {
-	// NOTE: separate the go test output from the compare output
-	fmt.Printf("\n\n------------------------------------------------------\n\n")
-
-	expectedResultBytes, err := os.ReadFile(expectedTestResultsFilename)
+// parseResults parses the log entries from the test results file.
+func parseResults(filename string) []*tlog.Entry {
+	f, err := os.Open(filename)
 	if err != nil {
-		fmt.Printf("[FATAL]: Failed to open file '%v': %v\n", expectedTestResultsFilename, err)
+		fmt.Printf("[FATAL]: Failed to open file '%v': %v\n", filename, err)
 		os.Exit(1)
 	}
-
-	actualResultBytes, err := os.ReadFile(actualTestResultsFilename)
+	defer f.Close()
+	entries, err := tlog.ParseEntries(f)
 	if err != nil {
-		fmt.Printf("[FATAL]: Failed to open file '%v': %v\n", actualTestResultsFilename, err)
+		fmt.Printf("[FATAL]: Failed to parse file '%v': %v\n", filename, err)
 		os.Exit(1)
 	}
+	return entries
+}
 
-	// NOTE: remove timestamps, because those are not comparable
-	re := regexp.MustCompile("[0-9]{4}-[0-9]{2}-[0-9]{2} [0-9]{2}:[0-9]{2}:[0-9]{2}.[0-9]{3} .*/[^:0-9{1-3}]")
-	expectedResultLines := re.Split(string(expectedResultBytes), -1)
-	actualResultLines := re.Split(string(actualResultBytes), -1)
+func main() {
+	color := flag.Bool("color", false, "Indicates whether to color the diff of the results")
+	context := flag.Int("context", golden.DefaultContext, "Number of unchanged lines shown around the changes in the diff of the results")
+	flag.Parse()
 
-	// NOTE: check if the results are the same length; if not find the missing/extra lines
-	expectedResultLinesCount := len(expectedResultLines)
-	actualResultLinesCount := len(actualResultLines)
+	// NOTE: separate the go test output from the compare output
+	fmt.Printf("\n\n------------------------------------------------------\n\n")
+
+	var testResultsDirs []string
+	for _, pattern := range testResultsDirPatterns {
+		dirs, _ := filepath.Glob(pattern)
+		testResultsDirs = append(testResultsDirs, dirs...)
+	}
+	if len(testResultsDirs) == 0 {
+		fmt.Println("[FATAL]: No test results found")
+		os.Exit(1)
+	}
 
 	passed := true
-	if expectedResultLinesCount != actualResultLinesCount {
-		passed = false
-		expectedLinesMap := make(map[string]struct{})
-		for _, l := range expectedResultLines {
-			expectedLinesMap[l] = struct{}{}
-		}
-		actualLinesMap := make(map[string]struct{})
-		for _, l := range actualResultLines {
-			actualLinesMap[l] = struct{}{}
+	for _, dir := range testResultsDirs {
+		expectedResults := parseResults(filepath.Join(dir, expectedTestResultsFilename))
+		actualResults := parseResults(filepath.Join(dir, actualTestResultsFilename))
 
+		// NOTE: compare test by test and normalize timestamps and paths, because those are not comparable
+		diff, err := golden.CompareEntries(expectedResults, actualResults,
+			golden.WithNormalizers(golden.Timestamps, golden.Paths, golden.Durations),
+			golden.WithContext(*context),
+			golden.WithColor(*color),
+		)
+		if err != nil {
+			fmt.Printf("[FATAL]: Failed to compare results in '%v': %v\n", dir, err)
+			os.Exit(1)
 		}
-		var missingLines []string
-		var extraLines []string
-		for _, line := range expectedResultLines {
-			if _, ok := actualLinesMap[line]; !ok {
-				missingLines = append(missingLines, line)
-			}
-		}
-		for _, line := range actualResultLines {
-			if _, ok := expectedLinesMap[line]; !ok {
-				extraLines = append(extraLines, line)
-			}
-		}
-		fmt.Printf("Expected '%v' lines in results, but got '%v'\n", expectedResultLinesCount, actualResultLinesCount)
-		if len(missingLines) != 0 {
-			fmt.Println("These are the missing lines:")
-			for _, line := range missingLines {
-				fmt.Printf("* %#v\n", line)
-			}
-		}
-		if len(extraLines) != 0 {
-			fmt.Println("These are the extra lines:")
-			for _, line := range extraLines {
-				fmt.Printf("* %#v\n", line)
-			}
-		}
-	} else {
-		// NOTE: check that there are no difference between expected and actual lines.
-		for i := 0; i < expectedResultLinesCount; i++ {
-			expectedResultLinesi := expectedResultLines[i]
-			actualResultLinesi := actualResultLines[i]
-			if expectedResultLinesi != actualResultLinesi {
-				fmt.Printf("Line %v: expected log entry '%v', but got '%v'\n", i, expectedResultLinesi, actualResultLinesi)
-				passed = false
-			}
+		if diff != "" {
+			passed = false
+			fmt.Printf("Results in '%v' differ:\n%v", dir, diff)
 		}
 	}
 
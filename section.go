@@ -0,0 +1,126 @@
+// Copyright 2023 Meelis Utt. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package tlog
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// maxReportedSections is the maximum number of sections in the summary of the slowest sections.
+const maxReportedSections = 5
+
+// section is a timed part of the test, see Logger.Section.
+type section struct {
+	name    string        // name of the section, prefixed by the names of the enclosing sections, eg "setup/connect db".
+	start   time.Time     // time when the section began.
+	elapsed time.Duration // duration of the section, set when the section ended.
+	ended   bool
+}
+
+// Section begins a timed section of the test called name and returns the function ending it:
+//
+//	end := tl.Section("connect db")
+//	defer end()
+//
+// A log entry with the "section" field is recorded when the section begins and ends, the latter with the "elapsed" field.
+// The log entries made while the section is running are indented by the number of running sections, in addition to the subtest depth.
+// Sections can be nested, the name of a nested section is prefixed by the names of the enclosing sections, eg "setup/connect db".
+// When the test fails or panics, a summary of the slowest sections is outputted after the log entries.
+//
+// The sections are shared by the loggers derived from the logger (see Logger.With),
+// so entries made by other goroutines while the section is running are indented as well.
+// Calling the returned function more than once has no effect.
+func (sl *Logger) Section(name string) func() {
+	sl.t.Helper()
+	sl.mu.Lock()
+	if len(sl.running) > 0 {
+		name = sl.running[len(sl.running)-1].name + "/" + name
+	}
+	s := &section{name: name, start: time.Now()}
+	sl.add(sl.sectionEntry(s, "begin"))
+	sl.running = append(sl.running, s)
+	sl.sections = append(sl.sections, s)
+	sl.mu.Unlock()
+
+	return func() {
+		sl.t.Helper()
+		sl.mu.Lock()
+		defer sl.mu.Unlock()
+		if s.ended {
+			return
+		}
+		s.elapsed, s.ended = time.Since(s.start), true
+		sl.running = slices.DeleteFunc(sl.running, func(r *section) bool { return r == s })
+		sl.add(sl.sectionEntry(s, "end", Field{Key: "elapsed", Value: s.elapsed}))
+	}
+}
+
+// InSection runs f in a timed section of the test called name, see Logger.Section.
+func (sl *Logger) InSection(name string, f func()) {
+	sl.t.Helper()
+	end := sl.Section(name)
+	defer end()
+	f()
+}
+
+// sectionEntry makes the log entry recording the beginning or the end of the section.
+func (sl *Logger) sectionEntry(s *section, msg string, fields ...Field) *Entry {
+	sl.t.Helper()
+	fields = append([]Field{{Key: "section", Value: s.name}}, fields...)
+	entry := makeEntry(sl.t, LevelInfo, joinFields(sl.fields, fields), "%s", msg)
+	entry.skip = sl.skip
+	return entry
+}
+
+// printSections outputs the summary of the slowest sections as a log entry and forgets the sections.
+// The sections that are still running are reported with the time elapsed so far.
+func (sl *Logger) printSections() {
+	sl.t.Helper()
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+	if len(sl.sections) == 0 {
+		return
+	}
+	sections := slices.Clone(sl.sections)
+	now := time.Now()
+	elapsed := func(s *section) time.Duration {
+		if s.ended {
+			return s.elapsed
+		}
+		return now.Sub(s.start)
+	}
+	slices.SortStableFunc(sections, func(a, b *section) int {
+		return cmp.Compare(elapsed(b), elapsed(a))
+	})
+
+	var width int
+	for _, s := range sections {
+		width = max(width, len(s.name))
+	}
+	var sb strings.Builder
+	sb.WriteString("slowest sections:")
+	for i, s := range sections {
+		if i == maxReportedSections {
+			fmt.Fprintf(&sb, "\n%v... and %v more", indentUnit, len(sections)-i)
+			break
+		}
+		fmt.Fprintf(&sb, "\n%v%-*v  %v", indentUnit, width, s.name, elapsed(s))
+		if !s.ended {
+			sb.WriteString(" (not ended)")
+		}
+	}
+	sl.output(sl.writesTo, &Entry{
+		Time:     now,
+		Location: "<sections>",
+		Name:     sl.t.Name(),
+		Level:    LevelInfo,
+		Message:  sb.String(),
+	})
+	sl.sections, sl.running = nil, nil
+}
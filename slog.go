@@ -0,0 +1,123 @@
+// Copyright 2023 Meelis Utt. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package tlog
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// Handler is a log/slog.Handler that records slog records as log entries of a Logger.
+// This enables passing a *slog.Logger to the code under test, so that its logs are outputted only when the test fails or panics.
+//
+// Slog levels are mapped to the tlog levels with the same value (see Level), slog attributes are recorded as entry fields
+// and the attributes inside groups get the group names as key prefixes, eg "req.method".
+// The entry's location is the call site of the slog record, not the location found by the logger.
+type Handler struct {
+	// filtered and unexported fields
+	sl     *Logger
+	fields []Field // fields added by WithAttrs.
+	groups []string
+}
+
+// NewHandler creates a new log/slog.Handler that records slog records as log entries of the provided logger.
+func NewHandler(sl *Logger) *Handler {
+	return &Handler{sl: sl}
+}
+
+// Slog creates a new *slog.Logger that records its logs as log entries of the logger.
+func (sl *Logger) Slog() *slog.Logger {
+	return slog.New(NewHandler(sl))
+}
+
+// Enabled reports that the handler handles records at all levels,
+// since the decision to output the log entries is made by the level's retention policy.
+func (h *Handler) Enabled(context.Context, slog.Level) bool {
+	return true
+}
+
+// Handle records the slog record as a log entry.
+func (h *Handler) Handle(_ context.Context, r slog.Record) error {
+	var fields []Field
+	r.Attrs(func(a slog.Attr) bool {
+		fields = appendAttr(fields, h.groups, a)
+		return true
+	})
+	entry := &Entry{
+		Time:    r.Time,
+		Name:    h.sl.t.Name(),
+		Level:   Level(r.Level),
+		Message: r.Message,
+		Fields:  joinFields(h.sl.fields, joinFields(h.fields, fields)),
+	}
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	if r.PC != 0 {
+		entry.pcs[0] = r.PC
+	} else {
+		entry.pcs = callers()
+	}
+	h.sl.mu.Lock()
+	defer h.sl.mu.Unlock()
+	h.sl.add(entry)
+	return nil
+}
+
+// WithAttrs returns a new handler, that adds the attributes to each log entry.
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	var fields []Field
+	for _, a := range attrs {
+		fields = appendAttr(fields, h.groups, a)
+	}
+	return &Handler{
+		sl:     h.sl,
+		fields: joinFields(h.fields, fields),
+		groups: h.groups,
+	}
+}
+
+// WithGroup returns a new handler, that adds the group name as a key prefix to the subsequent attributes.
+func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	groups := make([]string, 0, len(h.groups)+1)
+	groups = append(groups, h.groups...)
+	return &Handler{
+		sl:     h.sl,
+		fields: h.fields,
+		groups: append(groups, name),
+	}
+}
+
+// appendAttr appends the slog attribute to the fields, prefixing its key with the group names.
+// Group attributes are flattened and attributes with empty keys are ignored, as required by log/slog.
+func appendAttr(fields []Field, groups []string, a slog.Attr) []Field {
+	a.Value = a.Value.Resolve()
+	if a.Value.Kind() == slog.KindGroup {
+		attrs := a.Value.Group()
+		if len(attrs) == 0 {
+			return fields
+		}
+		if a.Key != "" {
+			groups = append(groups[:len(groups):len(groups)], a.Key)
+		}
+		for _, ga := range attrs {
+			fields = appendAttr(fields, groups, ga)
+		}
+		return fields
+	}
+	if a.Key == "" {
+		return fields
+	}
+	key := a.Key
+	if len(groups) > 0 {
+		key = strings.Join(groups, ".") + "." + key
+	}
+	return append(fields, Field{Key: key, Value: a.Value.Any()})
+}
@@ -0,0 +1,140 @@
+// Copyright 2023 Meelis Utt. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package tlog
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// spillFiles contains the paths of the spill files that are in use by the loggers of the current process.
+var spillFiles sync.Map
+
+// spillDir returns the directory of the spill files of the tests run in the current working directory,
+// ie of the current package, so that the spill files of different packages tested simultaneously don't mix.
+func spillDir() (string, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(wd))
+	return filepath.Join(os.TempDir(), "tlog-spill", hex.EncodeToString(sum[:8])), nil
+}
+
+// Spill enables writing the log entries to a spill file in addition to the logger's cache, as they are made.
+// The spill file is named after the test and the test binary's process, and it's removed in the test's cleanup, when the log entries are outputted as usual.
+// When the test binary is aborted before the cleanup, eg when a hanging test is killed by `go test -timeout`,
+// the spill file survives and the next test run can report it with ReportOrphanedSpills.
+// The log entries already made are spilled when Spill is called and the child loggers (see Logger.Child) spill as well.
+//
+// The log entries are spilled in the text format, see TextFormatter, regardless of the logger's Formatter.
+func (sl *Logger) Spill() {
+	sl.t.Helper()
+	dir, err := spillDir()
+	if err == nil {
+		err = os.MkdirAll(dir, 0750)
+	}
+	if err != nil {
+		sl.t.Fatalf("tlog: failed to create spill directory: %v", err)
+	}
+	f, err := os.CreateTemp(dir, fmt.Sprintf("%v.%v.*.log", url.PathEscape(sl.t.Name()), os.Getpid()))
+	if err != nil {
+		sl.t.Fatalf("tlog: failed to create spill file: %v", err)
+	}
+	spillFiles.Store(f.Name(), struct{}{})
+
+	sl.mu.Lock()
+	sl.spill = f
+	for _, log := range sl.logs {
+		sl.resolve(log)
+		TextFormatter{}.Format(f, log)
+	}
+	sl.mu.Unlock()
+
+	sl.t.Cleanup(func() {
+		sl.mu.Lock()
+		sl.spill = nil
+		sl.mu.Unlock()
+		f.Close()
+		os.Remove(f.Name())
+		spillFiles.Delete(f.Name())
+	})
+}
+
+// ReportOrphanedSpills writes the spill files left behind by the tests of the current package that did not complete,
+// eg because the test binary was killed by `go test -timeout`, to the io.Writer and removes them (see Logger.Spill).
+// Each spill file is preceded by a marker line with the name of the test that did not complete.
+// The spill files of the test binaries that are still running, eg when the package is tested simultaneously
+// from several terminals, are skipped. On plan9, the running test binaries can't be detected,
+// so the spill files are conservatively skipped.
+// It returns the number of reported spill files and the first error encountered.
+//
+// ReportOrphanedSpills is meant to be called from TestMain before running the tests:
+//
+//	func TestMain(m *testing.M) {
+//		tlog.ReportOrphanedSpills(os.Stdout)
+//		os.Exit(m.Run())
+//	}
+func ReportOrphanedSpills(wt io.Writer) (int, error) {
+	dir, err := spillDir()
+	if err != nil {
+		return 0, err
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, "*.log"))
+	if err != nil {
+		return 0, err
+	}
+	sort.Strings(paths)
+	var count int
+	for _, path := range paths {
+		if _, ok := spillFiles.Load(path); ok {
+			continue
+		}
+		name, pid := parseSpillName(path)
+		if pid == os.Getpid() || pid > 0 && processAlive(pid) {
+			continue
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return count, err
+		}
+		if _, err := fmt.Fprintf(wt, "=== tlog: test %q did not complete, spilled log entries:\n%s", name, content); err != nil {
+			return count, err
+		}
+		count++
+		if err := os.Remove(path); err != nil {
+			return count, err
+		}
+	}
+	return count, nil
+}
+
+// parseSpillName returns the name of the test and the process ID of the test binary from the spill file's path, as created by Logger.Spill.
+// The process ID is 0, when the path doesn't contain it.
+func parseSpillName(path string) (string, int) {
+	name := strings.TrimSuffix(filepath.Base(path), ".log")
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[:i]
+	}
+	var pid int
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		if n, err := strconv.Atoi(name[i+1:]); err == nil {
+			name, pid = name[:i], n
+		}
+	}
+	if unescaped, err := url.PathUnescape(name); err == nil {
+		name = unescaped
+	}
+	return name, pid
+}
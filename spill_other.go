@@ -0,0 +1,22 @@
+// Copyright 2023 Meelis Utt. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+//go:build plan9 || windows
+
+package tlog
+
+import "os"
+
+// processAlive reports whether the process with the given ID may be running.
+// On windows, os.FindProcess fails when the process doesn't exist.
+// On plan9, it always succeeds and the process can't be probed with a signal, so the process is conservatively treated as running,
+// ie the spill files of other test binaries are not reported.
+func processAlive(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	p.Release()
+	return true
+}
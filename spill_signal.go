@@ -0,0 +1,25 @@
+// Copyright 2023 Meelis Utt. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+//go:build !(plan9 || windows)
+
+package tlog
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// processAlive reports whether the process with the given ID is running, by sending it the null signal.
+// The process ID may have been reused by another process, in which case the spill file is reported by a later test run.
+func processAlive(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	defer p.Release()
+	err = p.Signal(syscall.Signal(0))
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
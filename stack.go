@@ -0,0 +1,112 @@
+// Copyright 2023 Meelis Utt. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package tlog
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// callStack returns the program counters of the calling goroutine's stack, starting from the caller of callStack.
+func callStack() []uintptr {
+	pcs := make([]uintptr, 64)
+	for {
+		n := runtime.Callers(2, pcs)
+		if n < len(pcs) {
+			return pcs[:n]
+		}
+		pcs = make([]uintptr, 2*len(pcs))
+	}
+}
+
+// formatStack formats the stack similarly to a goroutine stack trace, ie each frame as the function name followed by the indented location.
+// The frames of the testing and runtime packages and of the tlog package source files are left out.
+// When the stack contains a panic, only the frames from the panic site onwards are formatted.
+// It returns the formatted stack, the location of the first formatted frame, that isn't a helper function, in the given format
+// and whether the stack contains a panic.
+func formatStack(pcs []uintptr, format LocationFormat, helpers *helperSet) (string, string, bool) {
+	var sb strings.Builder
+	var location string
+	var panicking bool
+	frames := runtime.CallersFrames(pcs)
+	for more := len(pcs) > 0; more; {
+		var frame runtime.Frame
+		frame, more = frames.Next()
+		switch {
+		case frame.Function == "runtime.gopanic":
+			sb.Reset()
+			location, panicking = "", true
+			continue
+		case strings.HasPrefix(frame.Function, "runtime.") || strings.HasPrefix(frame.Function, "testing."):
+			continue
+		case internalFrame(frame):
+			continue
+		}
+		if location == "" && !helpers.contains(frame.Function) {
+			location = formatLocation(frame, format)
+		}
+		fmt.Fprintf(&sb, "%v(...)\n\t%v:%v\n", frame.Function, frame.File, frame.Line)
+	}
+	return strings.TrimSuffix(sb.String(), "\n"), location, panicking
+}
+
+// stackEntry creates a new log entry with the message followed by the formatted stack, see formatStack.
+// The location of the log entry is the first formatted frame.
+// The caller must hold the logger's lock.
+func (sl *Logger) stackEntry(level Level, msg string, pcs []uintptr) (*Entry, bool) {
+	stack, location, panicking := formatStack(pcs, sl.locationFormat, sl.helpers)
+	entry := &Entry{
+		Time:     time.Now(),
+		Location: location,
+		Name:     sl.t.Name(),
+		Level:    level,
+		Message:  msg + "\n" + stack,
+		Fields:   sl.fields,
+	}
+	if location == "" {
+		entry.pcs = callers()
+	}
+	return entry, panicking
+}
+
+// LogStack records the message followed by the stack of the calling goroutine in a new log entry.
+// The stack is trimmed, ie the frames of the testing and runtime packages are left out.
+// The entry is only outputted when the test fails or panics.
+func (sl *Logger) LogStack(msg string) {
+	sl.t.Helper()
+	pcs := callStack()
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+	entry, _ := sl.stackEntry(LevelInfo, msg, pcs)
+	sl.add(entry)
+}
+
+// panicMessage returns the message of the log entry recording the panic.
+func panicMessage(value []any) string {
+	if len(value) == 0 {
+		return "test panicked"
+	}
+	return fmt.Sprintf("panic: %v", value[0])
+}
+
+// recordPanic records the panic of the test as the final log entry and reports whether the test panicked.
+// The log entry is the one made by SetPanic, or it's made from the current stack, when it contains a panic.
+// It's appended without outputting it immediately, so that it's outputted after the other log entries.
+func (sl *Logger) recordPanic() bool {
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+	entry := sl.panicEntry
+	if entry == nil {
+		var panicking bool
+		entry, panicking = sl.stackEntry(LevelError, panicMessage(nil), callStack())
+		if !panicking {
+			return false
+		}
+	}
+	sl.logs = append(sl.logs, entry)
+	return true
+}
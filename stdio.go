@@ -0,0 +1,140 @@
+// Copyright 2023 Meelis Utt. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package tlog
+
+import (
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// stdioCaptured reports whether os.Stdout and os.Stderr are currently captured by a logger.
+var stdioCaptured atomic.Bool
+
+// testingLineRe matches the lines written by the testing package while the test is running:
+// the test framing (eg `=== RUN` lines of subtests and `--- PASS` lines of finished subtests),
+// the test2json markers (with -test.v=test2json flag) and the t.Log lines (with -test.v flag).
+var testingLineRe = regexp.MustCompile("^(=== (RUN|PAUSE|CONT|NAME|ARTIFACTS|ATTR) | *--- (PASS|FAIL|SKIP|BENCH): |.*[\x0e\x0f\x16]|( +)[^ ]+\\.go:[0-9]+: )")
+
+// CaptureStdio redirects the file descriptors of os.Stdout and os.Stderr through pipes for the rest of the test
+// and records each written line as a new log entry, tagged with the "stream" field ("stdout" or "stderr").
+// As with Log, the entries are only outputted when the test fails or panics.
+// The original file descriptors are restored in the test's cleanup.
+//
+// Since the file descriptors are redirected, the output written directly to them is captured as well,
+// eg by cgo code, by subprocesses inheriting them or through files referring to them opened before CaptureStdio was called.
+// The lines written by the testing package meanwhile, eg `=== RUN` lines of subtests and t.Log lines with -v flag,
+// are passed through to the original file descriptors, as is the logger's own output, when its io.Writer is os.Stdout or os.Stderr.
+// Other loggers writing to os.Stdout or os.Stderr are captured.
+// On platforms without dup2 system call, eg windows, the os.Stdout and os.Stderr variables are replaced instead,
+// so only the writes that go through them are captured.
+//
+// Since the file descriptors are process-wide, only one logger can capture them at a time
+// and CaptureStdio can't be used in parallel tests.
+func (sl *Logger) CaptureStdio() {
+	sl.t.Helper()
+	if !stdioCaptured.CompareAndSwap(false, true) {
+		sl.t.Fatal("tlog: os.Stdout and os.Stderr are already captured")
+	}
+	stdout, err := sl.captureStream(&os.Stdout, 1, "stdout")
+	if err != nil {
+		stdioCaptured.Store(false)
+		sl.t.Fatalf("tlog: failed to capture os.Stdout: %v", err)
+	}
+	stderr, err := sl.captureStream(&os.Stderr, 2, "stderr")
+	if err != nil {
+		stdout.stop()
+		stdioCaptured.Store(false)
+		sl.t.Fatalf("tlog: failed to capture os.Stderr: %v", err)
+	}
+	sl.t.Cleanup(func() {
+		stdout.stop()
+		stderr.stop()
+		stdioCaptured.Store(false)
+	})
+}
+
+// capturedStream is a standard stream redirected through a pipe, see Logger.CaptureStdio.
+type capturedStream struct {
+	sl      *Logger
+	std     **os.File
+	orig    *os.File // original stream, the lines written by the testing package are passed through to it.
+	restore func()
+	wg      sync.WaitGroup
+}
+
+// captureStream redirects the file descriptor fd of the standard stream *std through a pipe
+// and starts recording the lines read from the pipe.
+func (sl *Logger) captureStream(std **os.File, fd int, stream string) (*capturedStream, error) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	redirected := *std
+	orig, restore, err := redirectStdio(std, fd, w)
+	if err != nil {
+		r.Close()
+		w.Close()
+		return nil, err
+	}
+	cs := &capturedStream{sl: sl, std: std, orig: orig, restore: restore}
+
+	sl.mu.Lock()
+	if sl.writesTo == io.Writer(redirected) {
+		sl.writesTo = orig
+	}
+	sl.mu.Unlock()
+
+	cs.wg.Add(1)
+	go func() {
+		defer cs.wg.Done()
+		defer r.Close()
+		lw := &lineWriter{
+			sl:          sl,
+			location:    "<" + stream + ">",
+			fields:      []Field{{Key: "stream", Value: stream}},
+			passthrough: orig,
+		}
+		io.Copy(lw, r)
+		lw.flush()
+	}()
+	return cs, nil
+}
+
+// stop restores the standard stream and waits until the lines written to it are recorded.
+func (cs *capturedStream) stop() {
+	cs.restore()
+	cs.wg.Wait()
+	cs.sl.mu.Lock()
+	if cs.sl.writesTo == io.Writer(cs.orig) {
+		cs.sl.writesTo = *cs.std
+	}
+	cs.sl.mu.Unlock()
+	// NOTE: when the standard stream variable was replaced, the original stream is the standard stream itself.
+	if cs.orig != *cs.std {
+		cs.orig.Close()
+	}
+}
+
+// testingLine reports whether the line was written by the testing package, see testingLineRe.
+// The continuation lines of multiline t.Log messages, that are indented deeper than the first line, are reported as well.
+// The caller must hold the lineWriter's lock.
+func (w *lineWriter) testingLine(line string) bool {
+	if w.continuation != "" && strings.HasPrefix(line, w.continuation) {
+		return true
+	}
+	w.continuation = ""
+	match := testingLineRe.FindStringSubmatch(line)
+	if match == nil {
+		return false
+	}
+	if indentation := match[4]; indentation != "" {
+		w.continuation = indentation + indentUnit
+	}
+	return true
+}
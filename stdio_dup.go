@@ -0,0 +1,30 @@
+// Copyright 2023 Meelis Utt. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package tlog
+
+import (
+	"os"
+	"syscall"
+)
+
+// redirectStdio redirects the file descriptor fd of the standard stream *std to w and closes w.
+// It returns the original stream, a new file duplicated from fd before the redirection,
+// and the function restoring fd, that doesn't close the original stream.
+// The standard stream variable itself isn't changed, so that goroutines using it don't race with the redirection.
+func redirectStdio(std **os.File, fd int, w *os.File) (*os.File, func(), error) {
+	saved, err := syscall.Dup(fd)
+	if err != nil {
+		return nil, nil, err
+	}
+	syscall.CloseOnExec(saved)
+	if err := dup2(int(w.Fd()), fd); err != nil {
+		syscall.Close(saved)
+		return nil, nil, err
+	}
+	w.Close()
+	return os.NewFile(uintptr(saved), (*std).Name()), func() { dup2(saved, fd) }, nil
+}
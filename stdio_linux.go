@@ -0,0 +1,13 @@
+// Copyright 2023 Meelis Utt. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package tlog
+
+import "syscall"
+
+// dup2 makes newfd a copy of oldfd, closing newfd first if necessary.
+// Some linux architectures don't have the dup2 system call, so dup3 is used instead.
+func dup2(oldfd, newfd int) error {
+	return syscall.Dup3(oldfd, newfd, 0)
+}
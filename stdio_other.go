@@ -0,0 +1,20 @@
+// Copyright 2023 Meelis Utt. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+//go:build !(aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package tlog
+
+import "os"
+
+// redirectStdio replaces the standard stream *std with w, since redirecting the file descriptor fd is not supported on this platform.
+// It returns the original stream and the function restoring it and closing w.
+func redirectStdio(std **os.File, fd int, w *os.File) (*os.File, func(), error) {
+	orig := *std
+	*std = w
+	return orig, func() {
+		*std = orig
+		w.Close()
+	}, nil
+}
@@ -0,0 +1,84 @@
+// Copyright 2023 Meelis Utt. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package tlog
+
+import (
+	"log"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// stdLogFlags are the flags of the standard library loggers writing to the logger.
+// The caller location is resolved from the call stack instead of log.Llongfile flag,
+// so that it's formatted according to the logger's LocationFormat.
+const stdLogFlags = log.Lmsgprefix
+
+// stdLogPackage is the import path of the standard library log package.
+const stdLogPackage = "log"
+
+// stdLogRedirected reports whether the standard library log package output is currently redirected by a logger.
+var stdLogRedirected atomic.Bool
+
+// stdLogWriter is an io.Writer that records the lines written by a standard library logger as log entries.
+type stdLogWriter struct {
+	sl *Logger
+}
+
+// Write records the written line as a new log entry, with the location of the standard library logger's caller.
+func (w stdLogWriter) Write(p []byte) (int, error) {
+	entry := &Entry{
+		Time:    time.Now(),
+		Name:    w.sl.t.Name(),
+		Level:   LevelInfo,
+		Message: strings.TrimSuffix(string(p), "\n"),
+		Fields:  w.sl.fields,
+		skip:    w.sl.skip,
+	}
+	pcs := callers()
+	i := 0
+	for i < len(pcs)-1 && pcs[i] != 0 {
+		pkg := funcPackage(runtime.FuncForPC(pcs[i] - 1).Name())
+		if pkg != packagePath && pkg != stdLogPackage {
+			break
+		}
+		i++
+	}
+	copy(entry.pcs[:], pcs[i:])
+	w.sl.mu.Lock()
+	defer w.sl.mu.Unlock()
+	w.sl.add(entry)
+	return len(p), nil
+}
+
+// StdLogger creates a new standard library *log.Logger that records its output as log entries of the logger.
+// The location of the log entries is the caller of the *log.Logger methods, formatted according to the logger's LocationFormat.
+// As with Log, the entries are only outputted when the test fails or panics.
+func (sl *Logger) StdLogger() *log.Logger {
+	return log.New(stdLogWriter{sl: sl}, "", stdLogFlags)
+}
+
+// RedirectStdLog redirects the output of the standard library log package (eg log.Printf) to the logger for the rest of the test,
+// recording each log line as a new log entry with the caller location.
+// The previous output, flags and prefix of the log package are restored in the test's cleanup.
+//
+// Since the log package output is process-wide, only one logger can redirect it at a time
+// and RedirectStdLog can't be used in parallel tests.
+func (sl *Logger) RedirectStdLog() {
+	sl.t.Helper()
+	if !stdLogRedirected.CompareAndSwap(false, true) {
+		sl.t.Fatal("tlog: standard library log package output is already redirected")
+	}
+	output, flags, prefix := log.Writer(), log.Flags(), log.Prefix()
+	log.SetOutput(stdLogWriter{sl: sl})
+	log.SetFlags(stdLogFlags)
+	sl.t.Cleanup(func() {
+		log.SetOutput(output)
+		log.SetFlags(flags)
+		log.SetPrefix(prefix)
+		stdLogRedirected.Store(false)
+	})
+}
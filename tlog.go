@@ -12,66 +12,231 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"reflect"
 	"runtime"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
 
+// Level is the severity level of a log entry.
+// Each level has its own retention policy under the default ModeOnFail mode (see Mode):
+//   - LevelDebug: outputted only when the test fails or panics;
+//   - LevelInfo: outputted only when the test fails or panics, this is the level used by Log(f) and Print[f|ln](To);
+//   - LevelWarn: outputted when the test fails or panics, or when the test passes and -test.v flag is set;
+//   - LevelError: outputted immediately when the log entry is made.
+//
+// The levels between the named levels follow the policy of the closest lower named level, eg WARN+2 is retained as LevelWarn,
+// and selecting a named level, eg in GetLogEntries or Query, selects them as well.
+//
+// The level values match the values of the corresponding log/slog levels.
+type Level int
+
+const (
+	LevelDebug Level = -4
+	LevelInfo  Level = 0
+	LevelWarn  Level = 4
+	LevelError Level = 8
+)
+
+// String returns the name of the level in upper case, eg INFO.
+// Levels between the named levels are shown as the closest lower named level with an offset, eg WARN+2.
+func (l Level) String() string {
+	name := func(base string, val Level) string {
+		if val == 0 {
+			return base
+		}
+		return fmt.Sprintf("%v%+d", base, val)
+	}
+	switch {
+	case l < LevelInfo:
+		return name("DEBUG", l-LevelDebug)
+	case l < LevelWarn:
+		return name("INFO", l-LevelInfo)
+	case l < LevelError:
+		return name("WARN", l-LevelWarn)
+	default:
+		return name("ERROR", l-LevelError)
+	}
+}
+
+// named returns the closest named level at or below the level, eg LevelWarn for WARN+2.
+func (l Level) named() Level {
+	switch {
+	case l < LevelInfo:
+		return LevelDebug
+	case l < LevelWarn:
+		return LevelInfo
+	case l < LevelError:
+		return LevelWarn
+	default:
+		return LevelError
+	}
+}
+
+// matches reports whether the level is selected by the given level.
+// A named level selects the levels up to the next named level, eg LevelWarn selects WARN+2, other levels select only themselves.
+func (l Level) matches(level Level) bool {
+	if level == level.named() {
+		return l.named() == level
+	}
+	return l == level
+}
+
+// MarshalText encodes the level as its name, see Level.String.
+func (l Level) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+// UnmarshalText decodes the level from its name, as produced by Level.String.
+// The name is case insensitive, eg "warn+2" is decoded as LevelWarn+2.
+func (l *Level) UnmarshalText(text []byte) error {
+	name, offset := string(text), ""
+	if i := strings.IndexAny(name, "+-"); i >= 0 {
+		name, offset = name[:i], name[i:]
+	}
+	var level Level
+	switch strings.ToUpper(name) {
+	case "DEBUG":
+		level = LevelDebug
+	case "INFO":
+		level = LevelInfo
+	case "WARN":
+		level = LevelWarn
+	case "ERROR":
+		level = LevelError
+	default:
+		return fmt.Errorf("tlog: unknown level name %q", text)
+	}
+	if offset != "" {
+		n, err := strconv.Atoi(offset)
+		if err != nil {
+			return fmt.Errorf("tlog: invalid level offset in %q: %w", text, err)
+		}
+		level += Level(n)
+	}
+	*l = level
+	return nil
+}
+
 // Entry contains fields to construct a log entry.
 type Entry struct {
 	Time     time.Time // Timestamp when the log entry was made.
 	Location string    // Location (<filepath>:<row number>) where the log entry was made. Eg /foo/bar/baz:54.
 	Name     string    // Test's name, ie testing.T.Name().
+	Level    Level     // Severity level of the log entry.
 	Message  string    // Log message.
+	Fields   []Field   // Structured key/value attributes of the log entry, see Logger.With and Logger.Logw.
+
+	printed bool                 // log entry is already outputted, eg LevelError entries are outputted immediately.
+	pending bool                 // message is not yet formatted from format and args, see Entry.resolve.
+	format  string               // format of the pending message.
+	args    []any                // arguments of the pending message.
+	pcs     [callerDepth]uintptr // program counters of the callers, used to resolve the location when it's empty, see Entry.resolve.
+	skip    int                  // number of callers skipped when resolving the location, see Logger.CallerSkip.
+	section int                  // number of sections running when the log entry was made, used to indent the log entry, see Logger.Section.
 }
 
-// String returns log entry as a log string.
-// The format used is: <timestamp> <location> [<testname>]: <message>
+// String returns log entry as a log string, formatted by TextFormatter.
+// The format used is: <timestamp> <location> [<testname>] <level>[ <key>=<value>...]: <message>
 func (l *Entry) String() string {
-	return fmt.Sprintf(
-		"%v %v %v %v\n",
-		l.Time.UTC().Format("2006-01-02 15:04:05.000"),
-		l.Location,
-		fmt.Sprintf("[%v]:", l.Name),
-		l.Message,
-	)
+	var sb strings.Builder
+	TextFormatter{}.Format(&sb, l)
+	return sb.String()
+}
+
+// resolve formats the pending message and resolves the location of the log entry, that were deferred by makeEntry.
+// The location is formatted in the given format and the helper functions are skipped.
+// The caller must hold the lock of the logger containing the log entry, since resolve modifies the log entry.
+func (l *Entry) resolve(format LocationFormat, helpers *helperSet) {
+	if l.pending {
+		l.formatMessage()
+	}
+	if l.Location == "" && l.pcs[0] != 0 {
+		l.Location = framesLocation(l.pcs[:], l.skip, format, helpers)
+	}
+}
+
+// resolve resolves the log entry with the logger's location format and helper functions, see Entry.resolve.
+// The caller must hold the logger's lock.
+func (sl *Logger) resolve(entry *Entry) {
+	entry.resolve(sl.locationFormat, sl.helpers)
+}
+
+// formatMessage formats the pending message and releases the arguments.
+func (l *Entry) formatMessage() {
+	l.Message = fmt.Sprintf(l.format, l.args...)
+	l.pending, l.format, l.args = false, "", nil
 }
 
 // makeEntry is a function that creates new log entry.
-func makeEntry(t *testing.T, format string, args ...any) *Entry {
+// Formatting the message and resolving the location are deferred until the log entry is outputted or read, see Entry.resolve,
+// since most log entries are discarded when the test passes.
+func makeEntry(t testing.TB, level Level, fields []Field, format string, args ...any) *Entry {
 	t.Helper()
-	msg := fmt.Sprintf(format, args...)
-	var location string
-	for i := 0; ; i++ {
-		_, fpath, line, ok := runtime.Caller(i)
-		// MAYBE: think about how to handle !ok better
-		if !ok || !strings.Contains(fpath, "tlog.go") {
-			location = fmt.Sprintf("%v:%v", fpath, line)
-			break
-		}
-	}
 	return &Entry{
-		Time:     time.Now(),
-		Location: location,
-		Name:     t.Name(),
-		Message:  msg,
+		Time:    time.Now(),
+		Name:    t.Name(),
+		Level:   level,
+		Fields:  fields,
+		pending: true,
+		format:  format,
+		args:    args,
+		pcs:     callers(),
 	}
 }
 
 // Logger is an active logging object that stores log entries and outputs them to an io.Writer when test fails or panics.
 // Logger can be used simultaneously from multiple goroutines, it guarantees to serialize log entries to an internal cache.
+//
+// Logger works with every testing.TB implementation:
+//   - *testing.T: log entries are outputted when the test fails or panics;
+//   - *testing.B: log entries are outputted when the benchmark fails; when the benchmark loop uses Logger.Loop, only the entries from the last b.N iteration are kept;
+//   - *testing.F: log entries made while setting up the fuzz test are outputted when it fails; Logger.Fuzz attaches the log entries of the fuzz target to the failing input.
 type Logger struct {
 	// filtered and unexported fields
-	t            *testing.T
-	writesTo     io.Writer
-	logs         []*Entry
-	mu           sync.RWMutex
-	cleanupFuncs []func() // run defined funcs after logs are outputted.
-	testPaniced  bool     // in case recover was called and this value flipped, we can still output the logs.
+	*state
+	fields []Field // fields added to each log entry, see Logger.With.
+	skip   int     // number of callers skipped when resolving the location of the log entries, see Logger.CallerSkip.
+}
+
+// state contains the logger's state, that is shared between the logger and the loggers derived from it (see Logger.With).
+type state struct {
+	t              testing.TB
+	writesTo       io.Writer // when nil, log entries are reported through testing.TB.Log.
+	formatter      Formatter // when nil, DefaultFormatter is used.
+	logs           []*Entry
+	mu             sync.RWMutex
+	cleanupFuncs   []func()       // run defined funcs after logs are outputted.
+	testPaniced    bool           // in case recover was called and this value flipped, we can still output the logs.
+	panicEntry     *Entry         // log entry recording the panic, see Logger.SetPanic.
+	expectations   []expectation  // log assertions checked at the end of the test, see Logger.ExpectEntry.
+	iteration      int            // current benchmark loop iteration, see Logger.Loop.
+	loopStart      int            // mark of the log entries made before the benchmark loop started, see Logger.Loop and Logger.markIndex.
+	loopDropped    int            // number of log entries dropped when the current benchmark loop iteration started.
+	parent         *Logger        // logger of the parent test, see Logger.Child.
+	parentMark     int            // mark of the parent's log entries made before this logger was created, see Logger.markIndex.
+	depth          int            // number of ancestor loggers, used to indent the outputted log entries.
+	mode           *Mode          // when nil, the mode set by the -tlog.mode flag is used, see Logger.SetMode.
+	limit          Limit          // limits of the kept log entries, see Logger.LimitsTo.
+	size           int            // size of the kept log entries after the first ones, see Limit.
+	dropped        int            // number of log entries dropped due to the limit.
+	reported       int            // number of dropped log entries already outputted or summarized, eg by Logger.dump, that are left out of the summary.
+	eager          atomic.Bool    // format the log messages when the log entries are made, see Logger.FormatsEagerly.
+	locationFormat LocationFormat // format of the log entry locations, see Logger.FormatsLocations.
+	helpers        *helperSet     // functions skipped when resolving the log entry locations, see Logger.Helper.
+	spill          *os.File       // when not nil, log entries are also written to the spill file as they are made, see Logger.Spill.
+	sections       []*section     // timed sections of the test, see Logger.Section.
+	running        []*section     // sections that are running, in the order they began.
 }
 
+// fuzzLoggers contains the loggers created by Logger.Fuzz for the fuzz inputs that are currently being tested.
+// The key is the *testing.T of the fuzz input, so that New(t) inside the fuzz target returns the logger with attached input.
+var fuzzLoggers sync.Map
+
 // lnFormat creates a format string with `count` number of values.
 // Value format used is '%#v' to get the Go-representation of the values, if object is not a primitive.
 // Log and Print methods use the corresponding formatted methods.
@@ -85,13 +250,43 @@ func lnFormat(count int) string {
 	return strings.Join(s, " ")
 }
 
-// createLogger makes a new logger and makes sure that log entries are outputted when the test failed or paniced.
-func createLogger(t *testing.T, wt io.Writer) *Logger {
+// createLogger makes a new logger and makes sure that log entries are outputted according to the logger's mode, see Mode.
+// When wt is nil, os.Stdout is used, unless the logger was already created for a fuzz input by Logger.Fuzz.
+func createLogger(t testing.TB, wt io.Writer) *Logger {
 	t.Helper()
-	sl := &Logger{writesTo: wt, t: t}
+	if fl, ok := fuzzLoggers.Load(t); ok {
+		sl := fl.(*Logger)
+		if wt != nil {
+			sl.WritesTo(wt)
+		}
+		return sl
+	}
+	if wt == nil {
+		wt = os.Stdout
+	}
+	return newLogger(t, wt)
+}
+
+// newLogger makes a new logger without looking up existing fuzz input loggers.
+func newLogger(t testing.TB, wt io.Writer) *Logger {
+	t.Helper()
+	sl := &Logger{state: &state{writesTo: wt, t: t, helpers: &helperSet{}}}
+	sl.register()
 	t.Cleanup(func() {
-		if recover() != nil || t.Failed() || sl.testPaniced {
-			sl.print()
+		sl.unregister()
+		// NOTE: the testing package marks a panicking test as failed before running its cleanup,
+		// but the panic is still detected from the stack to record the stack from the panic site as the final log entry,
+		// and a recovered panic marked by SetPanic counts as a failure without failing the test, see Logger.recordPanic.
+		sl.checkExpectations()
+		failed := sl.recordPanic() || t.Failed()
+		if ok, levels := sl.retained(failed); ok {
+			if len(levels) == 0 {
+				sl.printContext()
+			}
+			sl.print(levels...)
+			if failed {
+				sl.printSections()
+			}
 		}
 		for _, fn := range sl.cleanupFuncs {
 			fn()
@@ -101,14 +296,67 @@ func createLogger(t *testing.T, wt io.Writer) *Logger {
 }
 
 // print outputs the log entries of the logger to io.Writer specified in the logger object.
-func (sl *Logger) print() {
+// When levels are provided, only the log entries with those levels are outputted.
+// Log entries that are already outputted are skipped and the dropped log entries are summarized, see Limit.
+func (sl *Logger) print(levels ...Level) {
 	sl.t.Helper()
 	sl.mu.Lock()
 	defer sl.mu.Unlock()
-	for _, log := range sl.logs {
-		fmt.Fprint(sl.writesTo, log)
+	for _, log := range filterLevels(sl.withDropped(sl.logs), levels) {
+		if !log.printed {
+			sl.output(sl.writesTo, log)
+		}
 	}
 	sl.logs = []*Entry{}
+	sl.size = 0
+	sl.dropped = 0
+	sl.reported = 0
+}
+
+// filterLevels returns the log entries with the given levels, see Level.matches.
+// When no levels are provided, all log entries are returned.
+func filterLevels(logs []*Entry, levels []Level) []*Entry {
+	if len(levels) == 0 {
+		return logs
+	}
+	var filtered []*Entry
+	for _, log := range logs {
+		for _, level := range levels {
+			if log.Level.matches(level) {
+				filtered = append(filtered, log)
+				break
+			}
+		}
+	}
+	return filtered
+}
+
+// output writes the log entry to the io.Writer, indented by the logger's depth (see Logger.Child)
+// and the number of sections running when the log entry was made (see Logger.Section).
+// When the io.Writer is nil, the log entry is reported through testing.TB.Log instead,
+// which attaches it to the test's own output (eg to the failing fuzz input).
+func (sl *Logger) output(wt io.Writer, log *Entry) (int, error) {
+	sl.t.Helper()
+	sl.resolve(log)
+	formatter := sl.formatter
+	if formatter == nil {
+		formatter = DefaultFormatter
+	}
+	depth := sl.depth + log.section
+	if wt != nil && depth == 0 {
+		return formatter.Format(wt, log)
+	}
+	var sb strings.Builder
+	if _, err := formatter.Format(&sb, log); err != nil {
+		return 0, err
+	}
+	text := indent(sb.String(), depth)
+	if wt == nil {
+		msg := strings.TrimSuffix(text, "\n")
+		sl.t.Log(msg)
+		return len(msg), nil
+	}
+	return io.WriteString(wt, text)
 }
 
 // WritesTo sets the loggers io.Writer to the specified one.
@@ -118,15 +366,60 @@ func (sl *Logger) WritesTo(wt io.Writer) {
 	sl.writesTo = wt
 }
 
+// FormatsWith sets the loggers Formatter to the specified one.
+// When the Formatter is nil, DefaultFormatter is used.
+func (sl *Logger) FormatsWith(f Formatter) {
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+	sl.formatter = f
+}
+
+// FormatsEagerly sets whether the log messages are formatted when the log entries are made.
+// By default, formatting is deferred until the log entries are outputted or read with GetLogEntries,
+// so that logging is cheap in passing tests, but the arguments are formatted with the values they have at that time.
+// Formatting eagerly snapshots the arguments, which is needed when logging mutable values, eg maps or pointers to structs, that change later in the test.
+func (sl *Logger) FormatsEagerly(eager bool) {
+	sl.eager.Store(eager)
+}
+
+// FormatsLocations sets the format of the log entry locations resolved from the call stack, see LocationFormat.
+func (sl *Logger) FormatsLocations(format LocationFormat) {
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+	sl.locationFormat = format
+}
+
+// CallerSkip returns a new logger sharing the state of the logger, whose log entries skip n additional callers
+// when resolving their location, so that helper functions wrapping the logger can report the location of their callers.
+// Only the callers outside of the tlog package are counted.
+func (sl *Logger) CallerSkip(n int) *Logger {
+	return &Logger{
+		state:  sl.state,
+		fields: sl.fields,
+		skip:   sl.skip + n,
+	}
+}
+
+// Helper marks the calling function as a helper function, similarly to testing.T.Helper.
+// When resolving the location of the log entries, the helper functions are skipped,
+// so that assertion helpers built on the logger report the location they were called from.
+// The helper functions are shared with the loggers of the subtests, see Logger.Child.
+// To skip the helper function in the test's own output as well, call testing.T.Helper too.
+func (sl *Logger) Helper() {
+	var pc [1]uintptr
+	runtime.Callers(2, pc[:])
+	sl.helpers.add(pc[0])
+}
+
 // NewWithWriter creates a new logger with provided io.Writer.
-func NewWithWriter(t *testing.T, wt io.Writer) *Logger {
+func NewWithWriter(t testing.TB, wt io.Writer) *Logger {
 	return createLogger(t, wt)
 }
 
 // New creates a new logger with os.Stdout as the io.Writer.
-func New(t *testing.T) *Logger {
-	return NewWithWriter(t, os.Stdout)
-
+// Inside a fuzz target run by Logger.Fuzz, New returns the logger attached to the fuzz input.
+func New(t testing.TB) *Logger {
+	return createLogger(t, nil)
 }
 
 // AddCleanupFunc adds function to list of functions to be run during the cleanup.
@@ -137,14 +430,50 @@ func (sl *Logger) AddCleanupFunc(fn func()) {
 	sl.cleanupFuncs = append(sl.cleanupFuncs, fn)
 }
 
+// logf records a new log entry with the given level and fields in addition to the logger's fields.
+// LevelError entries are outputted immediately, other entries are outputted according to the level's retention policy.
+func (sl *Logger) logf(level Level, fields []Field, format string, args ...any) {
+	sl.t.Helper()
+	entry := makeEntry(sl.t, level, joinFields(sl.fields, fields), format, args...)
+	entry.skip = sl.skip
+	if sl.eager.Load() {
+		entry.formatMessage()
+	}
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+	sl.add(entry)
+}
+
+// add records the log entry, outputting it immediately when it's a LevelError entry
+// and writing it to the spill file when spilling is enabled.
+// The oldest log entries are dropped, when the log entries exceed the logger's limits.
+// The caller must hold the logger's lock.
+func (sl *Logger) add(entry *Entry) {
+	sl.t.Helper()
+	entry.section = len(sl.running)
+	if entry.Level >= LevelError && sl.currentMode() != ModeNever {
+		sl.output(sl.writesTo, entry)
+		entry.printed = true
+	}
+	if sl.spill != nil {
+		sl.resolve(entry)
+		TextFormatter{}.Format(sl.spill, entry)
+	}
+	sl.logs = append(sl.logs, entry)
+	if sl.limit != (Limit{}) {
+		if sl.limit.MaxBytes > 0 && len(sl.logs) > sl.limit.First {
+			sl.size += entrySize(entry)
+		}
+		sl.trim()
+	}
+}
+
 // Logf formats its arguments according to the format, similarly to fmt.Printf, and records the text in a new log entry.
 // A final newline is added if not provided.
 // The entry is only outputted when the test fails or panics.
 func (sl *Logger) Logf(format string, args ...any) {
 	sl.t.Helper()
-	sl.mu.Lock()
-	defer sl.mu.Unlock()
-	sl.logs = append(sl.logs, makeEntry(sl.t, format, args...))
+	sl.logf(LevelInfo, nil, format, args...)
 }
 
 // Log formats its arguments in a default format, similarly to fmt.Println and records the text in a new log entry.
@@ -157,6 +486,62 @@ func (sl *Logger) Log(args ...any) {
 	sl.Logf(lnFormat(len(args)), args...)
 }
 
+// Debugf formats its arguments according to the format, similarly to Logf, and records the text in a new LevelDebug log entry.
+// The entry is only outputted when the test fails or panics.
+func (sl *Logger) Debugf(format string, args ...any) {
+	sl.t.Helper()
+	sl.logf(LevelDebug, nil, format, args...)
+}
+
+// Debug formats its arguments in a default format, similarly to Log, and records the text in a new LevelDebug log entry.
+// The entry is only outputted when the test fails or panics.
+func (sl *Logger) Debug(args ...any) {
+	sl.t.Helper()
+	sl.logf(LevelDebug, nil, lnFormat(len(args)), args...)
+}
+
+// Infof formats its arguments according to the format, similarly to Logf, and records the text in a new LevelInfo log entry.
+// The entry is only outputted when the test fails or panics.
+func (sl *Logger) Infof(format string, args ...any) {
+	sl.t.Helper()
+	sl.logf(LevelInfo, nil, format, args...)
+}
+
+// Info formats its arguments in a default format, similarly to Log, and records the text in a new LevelInfo log entry.
+// The entry is only outputted when the test fails or panics.
+func (sl *Logger) Info(args ...any) {
+	sl.t.Helper()
+	sl.logf(LevelInfo, nil, lnFormat(len(args)), args...)
+}
+
+// Warnf formats its arguments according to the format, similarly to Logf, and records the text in a new LevelWarn log entry.
+// The entry is outputted when the test fails or panics, or when the test passes and -test.v flag is set.
+func (sl *Logger) Warnf(format string, args ...any) {
+	sl.t.Helper()
+	sl.logf(LevelWarn, nil, format, args...)
+}
+
+// Warn formats its arguments in a default format, similarly to Log, and records the text in a new LevelWarn log entry.
+// The entry is outputted when the test fails or panics, or when the test passes and -test.v flag is set.
+func (sl *Logger) Warn(args ...any) {
+	sl.t.Helper()
+	sl.logf(LevelWarn, nil, lnFormat(len(args)), args...)
+}
+
+// Errorf formats its arguments according to the format, similarly to Logf, and records the text in a new LevelError log entry.
+// The entry is outputted immediately.
+func (sl *Logger) Errorf(format string, args ...any) {
+	sl.t.Helper()
+	sl.logf(LevelError, nil, format, args...)
+}
+
+// Error formats its arguments in a default format, similarly to Log, and records the text in a new LevelError log entry.
+// The entry is outputted immediately.
+func (sl *Logger) Error(args ...any) {
+	sl.t.Helper()
+	sl.logf(LevelError, nil, lnFormat(len(args)), args...)
+}
+
 // Printf formats its arguments according to the format, similarly to Printf, creates a log entry and outputs it to io.Writer specified in the logger.
 // It returns the number of bytes written and any write error.
 func (sl *Logger) Printf(format string, args ...any) (int, error) {
@@ -173,7 +558,9 @@ func (sl *Logger) PrintfTo(wt io.Writer, format string, args ...any) (int, error
 	sl.t.Helper()
 	sl.mu.RLock()
 	defer sl.mu.RUnlock()
-	return fmt.Fprint(wt, makeEntry(sl.t, format, args...).String())
+	entry := makeEntry(sl.t, LevelInfo, sl.fields, format, args...)
+	entry.skip = sl.skip
+	return sl.output(wt, entry)
 }
 
 // Println formats its arguments according to the format, similarly to Println, creates a log entry and outputs it to io.Writer specified in the logger.
@@ -198,17 +585,112 @@ func (sl *Logger) PrintlnTo(wt io.Writer, args ...any) (int, error) {
 	return sl.PrintfTo(wt, lnFormat(len(args)), args...)
 }
 
-// GetLogEntries returns list of log entries recorded in the logger.
-func (sl *Logger) GetLogEntries() []*Entry {
-	return sl.logs
+// GetLogEntries returns copies of the log entries recorded in the logger.
+// When levels are provided, only the log entries with those levels are returned, a named level includes the levels up to the next named level (see Level).
+// See Logger.Query for more ways to select the log entries.
+func (sl *Logger) GetLogEntries(levels ...Level) []*Entry {
+	return sl.Query(Query{Levels: levels})
 }
 
 // SetPanic marks the corresponding test as paniced.
 // When test handles the panic (ie recovers), then when SetPanic is called inside the recover,
 // the logger is still able to output the logs.
 // Logs are not outputted when SetPanic is not called when recovering from the panic.
-func (sl *Logger) SetPanic() {
+//
+// SetPanic records the optional panic value and the stack from the panic site as the final log entry,
+// the frames of the testing and runtime packages are left out of the stack.
+// When the test panics without recovering, the final log entry with the stack is recorded automatically,
+// but the panic value is only reported by the testing package.
+func (sl *Logger) SetPanic(value ...any) {
+	pcs := callStack()
 	sl.mu.Lock()
 	defer sl.mu.Unlock()
 	sl.testPaniced = true
+	sl.panicEntry, _ = sl.stackEntry(LevelError, panicMessage(value), pcs)
+}
+
+// Loop reports whether the benchmark loop should continue, similarly to the classic `for i := 0; i < b.N; i++` loop.
+// Each call starts a new iteration and discards the log entries made during the previous iteration,
+// so that only the log entries made before the loop and during the last b.N iteration are outputted when the benchmark fails.
+// Usage:
+//
+//	tl := tlog.New(b)
+//	for tl.Loop() {
+//		// ...
+//	}
+//
+// Loop panics when the logger was not created with *testing.B.
+func (sl *Logger) Loop() bool {
+	b, ok := sl.t.(*testing.B)
+	if !ok {
+		panic("tlog: Logger.Loop called on a logger not created with *testing.B")
+	}
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+	if sl.iteration >= b.N {
+		sl.iteration = 0
+		return false
+	}
+	if sl.iteration == 0 {
+		sl.loopStart = len(sl.logs) + sl.dropped
+	} else {
+		sl.discardIteration()
+	}
+	sl.loopDropped = sl.dropped
+	sl.iteration++
+	return true
+}
+
+// discardIteration discards the log entries made during the previous benchmark loop iteration, see Logger.Loop.
+// The log entries of the iteration dropped due to the logger's limits are not counted as dropped,
+// only the log entries made before the loop are, since the limits drop the oldest log entries first.
+// The caller must hold the logger's lock.
+func (sl *Logger) discardIteration() {
+	kept := sl.loopStart - sl.loopDropped // log entries made before the loop, that were kept when the iteration started.
+	preDropped := min(sl.dropped-sl.loopDropped, max(kept-sl.limit.First, 0))
+	sl.dropped = sl.loopDropped + preDropped
+	sl.reported = min(sl.reported, sl.dropped)
+	keep := sl.markIndex(sl.loopStart)
+	if sl.limit.MaxBytes > 0 {
+		for _, log := range sl.logs[max(keep, sl.limit.First):] {
+			sl.size -= entrySize(log)
+		}
+	}
+	clear(sl.logs[keep:])
+	sl.logs = sl.logs[:keep]
+}
+
+// Fuzz runs the fuzz target ff using testing.F.Fuzz, attaching the log entries of each fuzz input to that input.
+// The fuzz target has the same signature as for testing.F.Fuzz.
+// Inside the fuzz target, New(t) returns a logger that records the fuzz input as its first log entry
+// and reports the log entries through t.Log when the input fails, so that they are shown with the failing input.
+//
+// Fuzz panics when the logger was not created with *testing.F.
+func (sl *Logger) Fuzz(ff any) {
+	sl.t.Helper()
+	f, ok := sl.t.(*testing.F)
+	if !ok {
+		panic("tlog: Logger.Fuzz called on a logger not created with *testing.F")
+	}
+	pcs := callers()
+	fn := reflect.ValueOf(ff)
+	if fn.Kind() != reflect.Func || fn.Type().NumIn() == 0 || fn.Type().In(0) != reflect.TypeOf((*testing.T)(nil)) {
+		// NOTE: let testing.F.Fuzz report the invalid fuzz target.
+		f.Fuzz(ff)
+		return
+	}
+	f.Fuzz(reflect.MakeFunc(fn.Type(), func(args []reflect.Value) []reflect.Value {
+		t := args[0].Interface().(*testing.T)
+		inputs := make([]any, len(args)-1)
+		for i, arg := range args[1:] {
+			inputs[i] = arg.Interface()
+		}
+		fl := newLogger(t, nil)
+		entry := makeEntry(t, LevelInfo, nil, "fuzz input: "+lnFormat(len(inputs)), inputs...)
+		entry.pcs = pcs
+		fl.logs = append(fl.logs, entry)
+		fuzzLoggers.Store(t, fl)
+		defer fuzzLoggers.Delete(t)
+		return fn.Call(args)
+	}).Interface())
 }
@@ -14,24 +14,39 @@
 // If there are new features and tests added,
 // then we can make a new snapshot of expected behavior
 // by using the -record flag when running the tests.
-// NOTE: The tests for this package need be run sequentially,
-// since expected test results should be in deterministic order.
-// However, this doesn't mean that tlog package can't be used in
-// conjunction with t.Parallel()
+// NOTE: The results are compared test by test,
+// so the tests can be run in any order (eg with -shuffle=on) and in parallel.
 package tlog_test
 
 import (
+	"bufio"
+	"context"
 	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"io/fs"
+	"log"
+	"log/slog"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/moledoc/tlog"
 )
 
+// subprocessEnv is the environment variable containing the name of the test,
+// that is run in a separate test binary process by runSubprocess, eg to abort or crash the test binary.
+const subprocessEnv = "TLOG_TEST_SUBPROCESS"
+
+// subprocessOutputEnv is the environment variable containing the file, where the test run by runSubprocess writes its logs.
+const subprocessOutputEnv = "TLOG_TEST_SUBPROCESS_OUTPUT"
+
 var (
 	record                      bool
 	testResultsDir              string = "test_results"
@@ -57,6 +72,17 @@ func TestMain(m *testing.M) {
 	flag.BoolVar(&record, "record", false, "Indicates whether to record new test results or not")
 	flag.Parse()
 
+	// NOTE: the test binary is run by runSubprocess, don't touch the test results
+	if os.Getenv(subprocessEnv) != "" {
+		os.Exit(m.Run())
+	}
+
+	// NOTE: report the spill files of the tests that didn't complete in the previous run
+	tlog.ReportOrphanedSpills(os.Stdout)
+
+	// NOTE: output the logs of the running tests, when tests time out or the test binary is killed by SIGQUIT
+	tlog.InstallDumpHook(time.Second)
+
 	// NOTE: check if test_results dir exist
 	if _, err := os.Stat(testResultsDir); errors.Is(err, fs.ErrNotExist) {
 		if err := os.Mkdir(testResultsDir, 0750); err != nil {
@@ -98,6 +124,15 @@ func setupTestcase(t *testing.T) (*tlog.Logger, *os.File) {
 	return tl, f
 }
 
+// runSubprocess runs the test in a separate test binary process, that writes its logs to the output file.
+// It reports whether the test binary failed.
+func runSubprocess(t *testing.T, output string) bool {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], "-test.run=^"+t.Name()+"$")
+	cmd.Env = append(os.Environ(), subprocessEnv+"="+t.Name(), subprocessOutputEnv+"="+output)
+	return cmd.Run() != nil
+}
+
 func setupTestcaseStdout(t *testing.T) *tlog.Logger {
 	t.Helper()
 	return tlog.New(t)
@@ -124,6 +159,468 @@ func TestLogs(t *testing.T) {
 	t.Fail()
 }
 
+// TestLevelsNoFail should output only error entries and, since tests are run with -v flag, warning entries.
+func TestLevelsNoFail(t *testing.T) {
+	tl, _ := setupTestcase(t)
+	tl.Debugf("%v", "debug")
+	tl.Infof("%v", "info")
+	tl.Warnf("%v", "warn")
+	tl.Errorf("%v", "error")
+	tl.Debug("debug")
+	tl.Info("info")
+	tl.Warn("warn")
+	tl.Error("error")
+}
+
+// TestLevelOffsetsNoFail should output only the entries at or above WARN, since tests are run with -v flag,
+// ie levels between the named levels are retained as the closest lower named level.
+func TestLevelOffsetsNoFail(t *testing.T) {
+	tl, _ := setupTestcase(t)
+	logger := tl.Slog()
+	logger.Log(context.Background(), slog.LevelInfo+2, "info+2")
+	logger.Log(context.Background(), slog.LevelWarn+2, "warn+2")
+	logger.Log(context.Background(), slog.LevelError+2, "error+2")
+}
+
+// TestLevels should output error entries immediately and the rest of the entries, since test fails.
+func TestLevels(t *testing.T) {
+	tl, _ := setupTestcase(t)
+	tl.Debugf("%v", "debug")
+	tl.Infof("%v", "info")
+	tl.Warnf("%v", "warn")
+	tl.Errorf("%v", "error")
+	tl.Debug("debug")
+	tl.Info("info")
+	tl.Warn("warn")
+	tl.Error("error")
+	tl.Println(len(tl.GetLogEntries()), len(tl.GetLogEntries(tlog.LevelWarn, tlog.LevelError)))
+	t.Fail()
+}
+
+// TestFields should output log entries with fields, since test fails.
+func TestFields(t *testing.T) {
+	tl, _ := setupTestcase(t)
+	tl.Logw("no fields")
+	tl.Logw("fields", "user", 42, "op", "get user", "ok", true)
+	tl.Logw("odd fields", "user")
+	tl.Logw("fields as values", tlog.Field{Key: "empty", Value: ""}, 1.5)
+	utl := tl.With("user", 42)
+	utl.Log("one")
+	utl.With("op", "a=b").Logw("two", "err", errors.New("failed: timeout"))
+	tl.Log("without fields")
+	tl.Println(len(tl.GetLogEntries()[4].Fields), tl.GetLogEntries()[4].Fields[0].Value)
+	t.Fail()
+}
+
+// TestSlog should output log entries made through log/slog, since test fails.
+func TestSlog(t *testing.T) {
+	tl, _ := setupTestcase(t)
+	logger := tl.Slog()
+	logger.Debug("debug", "user", 42)
+	logger.Info("info", slog.Group("req", "method", "GET", "path", "/"))
+	logger.With("user", 42).WithGroup("db").Warn("warn", "table", "users", slog.Group("", "inlined", true))
+	logger.Log(context.Background(), slog.LevelInfo+2, "info+2")
+	t.Fail()
+}
+
+// TestSubtests should output the log entries of the failing subtests, preceded by the parent's log entries made before the subtest,
+// but not the log entries of the passing subtests.
+func TestSubtests(t *testing.T) {
+	tl, _ := setupTestcase(t)
+	tl.Log("before subtests")
+	tl.Run("pass", func(tl *tlog.Logger) {
+		tl.Log("passing subtest")
+	})
+	tl.Logf("between\nsubtests")
+	tl.Run("fail", func(tl *tlog.Logger) {
+		tl.Log("failing subtest")
+		tl.Logf("multiline\nmessage")
+		tl.TB().Fail()
+	})
+	tl.Run("nested", func(tl *tlog.Logger) {
+		tl.Log("nested subtest")
+		tl.Run("deep", func(tl *tlog.Logger) {
+			tl.Log("deep subtest")
+			tl.TB().Fail()
+		})
+		tl.Log("after deep subtest")
+	})
+	tl.Log("after subtests")
+}
+
+// TestCaptureStdout should output the lines written to os.Stdout, since test fails.
+func TestCaptureStdout(t *testing.T) {
+	tl, _ := setupTestcase(t)
+	tl.CaptureStdio()
+	fmt.Println("one")
+	fmt.Printf("two\nthree\n\n")
+	fmt.Print("without newline")
+	t.Fail()
+}
+
+// TestCaptureStderr should output the lines written to os.Stderr, since test fails.
+func TestCaptureStderr(t *testing.T) {
+	tl, _ := setupTestcase(t)
+	tl.CaptureStdio()
+	fmt.Fprintln(os.Stderr, "one")
+	fmt.Fprintf(os.Stderr, "%v\t%v\n", "two", "three")
+	t.Fail()
+}
+
+// TestCaptureStdioDescriptors should output the lines written to the file descriptors, through a file opened before capturing
+// and by a subprocess, but not the lines written by the testing package, since test fails.
+func TestCaptureStdioDescriptors(t *testing.T) {
+	tl, _ := setupTestcase(t)
+	stdout := os.Stdout
+	tl.CaptureStdio()
+	fmt.Fprintln(stdout, "through file opened before")
+	cmd := exec.Command(os.Args[0], "-test.run=^$")
+	cmd.Env = append(os.Environ(), subprocessEnv+"="+t.Name())
+	cmd.Stdout = os.Stdout
+	tl.Log(cmd.Run())
+	t.Run("subtest", func(t *testing.T) {
+		t.Log("testing package\nmultiline")
+	})
+	fmt.Println("after subtest")
+	t.Fail()
+}
+
+// TestCaptureStdioNoFail shouldn't output anything, since test doesn't fail.
+func TestCaptureStdioNoFail(t *testing.T) {
+	tl, _ := setupTestcase(t)
+	tl.CaptureStdio()
+	fmt.Println("one")
+	fmt.Fprintln(os.Stderr, "one")
+}
+
+// TestStdLog should output the lines written by the standard library log package, since test fails.
+func TestStdLog(t *testing.T) {
+	tl, _ := setupTestcase(t)
+	logger := tl.StdLogger()
+	logger.Printf("%v", "one")
+	logger.SetPrefix("prefix: ")
+	logger.Println("two", "three")
+	tl.RedirectStdLog()
+	log.Printf("%v", "four")
+	log.Println("five\nsix")
+	t.Fail()
+}
+
+// TestStdLogLocationFormat should output the lines written by the standard library log package with the logger's location format,
+// and the log package prefix restored after the test, since test fails.
+func TestStdLogLocationFormat(t *testing.T) {
+	tl, _ := setupTestcase(t)
+	tl.FormatsLocations(tlog.LocationBase)
+	log.SetPrefix("before: ")
+	t.Cleanup(func() {
+		tl.Log(log.Prefix())
+		log.SetPrefix("")
+	})
+	tl.RedirectStdLog()
+	log.SetPrefix("during: ")
+	log.Print("one")
+	tl.StdLogger().Print("two")
+	t.Fail()
+}
+
+// TestWriter should output the lines written to the logger's io.Writer, including the partial line, since test fails.
+func TestWriter(t *testing.T) {
+	tl, _ := setupTestcase(t)
+	w := tl.Writer("component: ")
+	fmt.Fprint(w, "one\ntw")
+	fmt.Fprint(w, "o\nthree")
+	fmt.Fprintf(w, "\n\nfour")
+	t.Fail()
+}
+
+// TestSpill should output the log entries spilled by the aborted test binary, since test fails.
+func TestSpill(t *testing.T) {
+	if os.Getenv(subprocessEnv) == t.Name() {
+		tl := tlog.New(t)
+		tl.Log("before spilling")
+		tl.Spill()
+		tl.Logf("multiline\nmessage")
+		os.Exit(1) // NOTE: abort the test binary, so that the cleanup isn't run
+	}
+	tl, _ := setupTestcase(t)
+	tl.Log(runSubprocess(t, os.DevNull))
+	var sb strings.Builder
+	n, err := tlog.ReportOrphanedSpills(&sb)
+	tl.Log(n, err)
+	fmt.Fprint(tl.Writer("report: "), sb.String())
+	t.Fail()
+}
+
+// TestSpillRunning should output the spill file only after the test binary spilling it has stopped, since test fails.
+func TestSpillRunning(t *testing.T) {
+	if os.Getenv(subprocessEnv) == t.Name() {
+		tl := tlog.New(t)
+		tl.Spill()
+		tl.Log("while running")
+		fmt.Println("spilled")
+		time.Sleep(time.Minute) // NOTE: the test binary is killed by the parent test
+	}
+	tl, _ := setupTestcase(t)
+	cmd := exec.Command(os.Args[0], "-test.run=^"+t.Name()+"$")
+	cmd.Env = append(os.Environ(), subprocessEnv+"="+t.Name())
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	line, err := bufio.NewReader(stdout).ReadString('\n')
+	tl.Log(line, err)
+	var sb strings.Builder
+	n, err := tlog.ReportOrphanedSpills(&sb)
+	tl.Log("running:", n, err)
+	cmd.Process.Kill()
+	cmd.Wait()
+	n, err = tlog.ReportOrphanedSpills(&sb)
+	tl.Log("killed:", n, err)
+	fmt.Fprint(tl.Writer("report: "), sb.String())
+	t.Fail()
+}
+
+// TestDumpLiveLoggersNoFail should output the log entries made before the dump, even though the test doesn't fail.
+func TestDumpLiveLoggersNoFail(t *testing.T) {
+	tl, _ := setupTestcase(t)
+	tl.Log("before dump")
+	tl.Errorf("error before dump")
+	tlog.DumpLiveLoggers()
+	tl.Log("after dump")
+}
+
+// TestDumpLiveLoggersSubtest should output the parent's log entries made before the subtest once, when dumping,
+// followed by the subtest's log entries and the parent's log entries made after the dump, since the subtest fails.
+func TestDumpLiveLoggersSubtest(t *testing.T) {
+	tl, _ := setupTestcase(t)
+	tl.Log("before subtest")
+	tl.Run("fail", func(ctl *tlog.Logger) {
+		tlog.DumpLiveLoggers()
+		tl.Log("after dump")
+		ctl.Log("failing subtest")
+		ctl.TB().Fail()
+	})
+}
+
+// TestModeAlwaysNoFail should output logged values, even though the test doesn't fail.
+func TestModeAlwaysNoFail(t *testing.T) {
+	tl, _ := setupTestcase(t)
+	tl.SetMode(tlog.ModeAlways)
+	tl.Debug("one")
+	tl.Log("two")
+}
+
+// TestModeNever shouldn't output anything, even though the test fails.
+func TestModeNever(t *testing.T) {
+	tl, _ := setupTestcase(t)
+	tl.SetMode(tlog.ModeNever)
+	tl.Log("one")
+	tl.Error("two")
+	t.Fail()
+}
+
+// TestModeOnSkip should output logged values, since test is skipped.
+func TestModeOnSkip(t *testing.T) {
+	tl, _ := setupTestcase(t)
+	tl.SetMode(tlog.ModeOnSkip)
+	tl.Log("one")
+	t.Skip("skipping")
+}
+
+// TestLimit should output the first and last logged values with the number of dropped values in between, since test fails.
+func TestLimit(t *testing.T) {
+	tl, _ := setupTestcase(t)
+	tl.LimitsTo(tlog.Limit{First: 2, Last: 3})
+	for i := 0; i < 10; i++ {
+		tl.Log(i)
+	}
+	t.Fail()
+}
+
+// TestLimitBytes should output the last logged values fitting into the byte budget, since test fails.
+func TestLimitBytes(t *testing.T) {
+	tl, _ := setupTestcase(t)
+	tl.Log("before limit")
+	tl.LimitsTo(tlog.Limit{MaxBytes: 8})
+	tl.Logw("one", "k", "v")
+	tl.Logf("two")
+	tl.Logf("three")
+	t.Fail()
+}
+
+// TestFormatsEagerly should output the values the arguments had when logging eagerly, and when outputting otherwise, since test fails.
+func TestFormatsEagerly(t *testing.T) {
+	tl, _ := setupTestcase(t)
+	m := map[string]int{"k": 1}
+	tl.Log(m)
+	tl.FormatsEagerly(true)
+	tl.Log(m)
+	m["k"] = 2
+	t.Fail()
+}
+
+// TestFormatsEagerlyChild should output the value the argument had when the child logger logged it, since subtest fails.
+func TestFormatsEagerlyChild(t *testing.T) {
+	tl, _ := setupTestcase(t)
+	tl.FormatsEagerly(true)
+	tl.Run("child", func(tl *tlog.Logger) {
+		m := map[string]int{"k": 1}
+		tl.Log(m)
+		m["k"] = 2
+		tl.TB().Fail()
+	})
+}
+
+// TestLocationFormats should output the locations in each format, since subtests fail.
+func TestLocationFormats(t *testing.T) {
+	tl, _ := setupTestcase(t)
+	for _, format := range []tlog.LocationFormat{tlog.LocationFull, tlog.LocationModule, tlog.LocationBase, tlog.LocationFunc} {
+		tl.Run(format.String(), func(tl *tlog.Logger) {
+			tl.FormatsLocations(format)
+			tl.Log(format.String())
+			tl.TB().Fail()
+		})
+	}
+}
+
+// logHelper is a helper function, that logs the message with the location of its caller.
+func logHelper(tl *tlog.Logger, msg string) {
+	tl.CallerSkip(1).Log(msg)
+}
+
+// TestCallerSkip should output the location of the helper function's caller, since test fails.
+func TestCallerSkip(t *testing.T) {
+	tl, _ := setupTestcase(t)
+	tl.Log("direct")
+	logHelper(tl, "through helper")
+	logHelper(tl.With("k", "v"), "through helper with fields")
+	t.Fail()
+}
+
+// assertPositive is an assertion helper built on the logger, that logs the result of the check.
+func assertPositive(tl *tlog.Logger, n int) {
+	tl.Helper()
+	if n <= 0 {
+		tl.Errorf("expected positive number, got %v", n)
+		return
+	}
+	tl.Logf("%v is positive", n)
+}
+
+// assertAllPositive is an assertion helper calling another helper.
+func assertAllPositive(tl *tlog.Logger, ns ...int) {
+	tl.Helper()
+	for _, n := range ns {
+		assertPositive(tl, n)
+	}
+}
+
+// TestHelper should output the locations where the helpers were called from, since test fails.
+func TestHelper(t *testing.T) {
+	tl, _ := setupTestcase(t)
+	assertPositive(tl, 1)
+	assertAllPositive(tl, 2, -3)
+	tl.Run("subtest", func(tl *tlog.Logger) {
+		assertPositive(tl, 4)
+		tl.TB().Fail()
+	})
+	t.Fail()
+}
+
+// TestQuery should output the logged values and the ones selected by the queries, since test fails.
+func TestQuery(t *testing.T) {
+	tl, _ := setupTestcase(t)
+	tl.Logw("request", "path", "/users", "status", 200)
+	tl.Logw("request", "path", "/orders", "status", 500)
+	tl.Warnf("slow request")
+	for _, entry := range tl.Query(tlog.Query{Fields: []tlog.Field{{Key: "status", Value: 500}}}) {
+		tl.Logf("status 500: %v", entry.Fields)
+	}
+	for entry := range tl.Entries(tlog.Query{Message: regexp.MustCompile(`^slow`), Levels: []tlog.Level{tlog.LevelWarn}}) {
+		tl.Log("slow:", entry.Message)
+	}
+	t.Fail()
+}
+
+// TestGetLogEntriesWhileLogging shouldn't output anything, since reading the log entries while logging is safe
+// and changing the returned log entries doesn't change the logger's state.
+func TestGetLogEntriesWhileLogging(t *testing.T) {
+	tl, _ := setupTestcase(t)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				tl.Logw("logging", "goroutine", i)
+			}
+		}()
+	}
+	for i := 0; i < 10; i++ {
+		for _, entry := range tl.GetLogEntries() {
+			entry.Message = "changed"
+			entry.Fields[0].Value = -1
+		}
+	}
+	wg.Wait()
+	for _, entry := range tl.GetLogEntries() {
+		if entry.Message != "logging" || entry.Fields[0].Value == -1 {
+			t.Fatalf("log entry changed through GetLogEntries: %v", entry)
+		}
+	}
+}
+
+// recordingTB is a testing.TB, that records the errors instead of reporting them.
+type recordingTB struct {
+	testing.TB
+	errors []string
+}
+
+func (rt *recordingTB) Error(args ...any) {
+	rt.errors = append(rt.errors, fmt.Sprint(args...))
+}
+
+// TestExpectations should output the failure messages of the failed log assertions, since test fails.
+func TestExpectations(t *testing.T) {
+	tl, _ := setupTestcase(t)
+	rt := &recordingTB{TB: t}
+	t.Cleanup(func() {
+		// NOTE: runs after the log assertions of the logger below are checked
+		for _, err := range rt.errors {
+			tl.Logf("%v", err)
+		}
+		t.Fail()
+	})
+	el := tlog.NewWithWriter(rt, io.Discard)
+	retry := tlog.Query{Message: regexp.MustCompile(`^retry`), Levels: []tlog.Level{tlog.LevelWarn}}
+	el.ExpectCount(retry, 2)                                                     // NOTE: fails, since only one retry warning is logged
+	el.ExpectEntry(tlog.Query{Fields: []tlog.Field{{Key: "attempt", Value: 1}}}) // NOTE: holds
+	el.ForbidEntry(tlog.Query{Levels: []tlog.Level{tlog.LevelError}})            // NOTE: fails
+	el.ForbidEntry(tlog.MatcherFunc(func(entry *tlog.Entry) bool {               // NOTE: holds
+		return strings.Contains(entry.Message, "panic")
+	}))
+	el.With("attempt", 1).Warnf("retry")
+	el.Infof("retry")
+	el.Warnf("slow request")
+	el.Errorf("gave up")
+}
+
+// TestExpectationsMatcherUsesLogger shouldn't output anything, since test doesn't fail.
+// The matcher uses the logger and modifies the log entry it is given, which must neither deadlock nor change the log entry seen by the next matcher.
+func TestExpectationsMatcherUsesLogger(t *testing.T) {
+	el := tlog.NewWithWriter(t, io.Discard)
+	el.ExpectEntry(tlog.MatcherFunc(func(entry *tlog.Entry) bool {
+		matches := len(el.GetLogEntries()) == 1 && entry.Message == "retry"
+		entry.Message = "changed"
+		return matches
+	}))
+	el.ExpectEntry(tlog.Query{Message: regexp.MustCompile(`^retry$`)})
+	el.Infof("retry")
+}
+
 // TestPrints should output logged values, regardless if the test fails
 func TestPrints(t *testing.T) {
 	tl, f := setupTestcase(t)
@@ -234,6 +731,67 @@ func TestPanicFromSubFunc(t *testing.T) {
 	}()
 }
 
+// TestPanicValue should output logged values followed by the panic value and the stack, since SetPanic is called.
+func TestPanicValue(t *testing.T) {
+	tl, _ := setupTestcase(t)
+	tl.Log("before panic")
+	defer func() {
+		tl.SetPanic(recover())
+	}()
+	panic("boom")
+}
+
+// TestPanicUnrecovered should output the logs of the test, that panicked in the test binary run by it, including the panic stack, since test fails.
+func TestPanicUnrecovered(t *testing.T) {
+	if os.Getenv(subprocessEnv) == t.Name() {
+		f, err := os.Create(os.Getenv(subprocessOutputEnv))
+		if err != nil {
+			t.Fatal(err)
+		}
+		tl := tlog.NewWithWriter(t, f)
+		tl.Log("before panic")
+		var m map[string]int
+		m["k"] = 1 // NOTE: panics, since map is nil
+	}
+	tl, _ := setupTestcase(t)
+	output := filepath.Join(t.TempDir(), "output.log")
+	tl.Log(runSubprocess(t, output))
+	content, err := os.ReadFile(output)
+	tl.Log(err)
+	fmt.Fprint(tl.Writer("subprocess: "), string(content))
+	t.Fail()
+}
+
+// TestLogStack should output the logged stack without the testing and runtime frames, since test fails.
+func TestLogStack(t *testing.T) {
+	tl, _ := setupTestcase(t)
+	func() {
+		tl.LogStack("inside func")
+	}()
+	t.Fail()
+}
+
+// TestSections should output the section entries indented by the running sections and the summary of the slowest sections, since test fails.
+func TestSections(t *testing.T) {
+	tl, _ := setupTestcase(t)
+	tl.Log("before sections")
+	end := tl.Section("setup")
+	tl.Log("setting up")
+	tl.InSection("connect db", func() {
+		tl.Log("connecting")
+		time.Sleep(10 * time.Millisecond)
+	})
+	end()
+	end() // no effect
+	tl.InSection("request", func() {
+		tl.With("status", 200).Log("request done")
+	})
+	tl.Section("teardown")
+	tl.Log("tearing down")
+	time.Sleep(5 * time.Millisecond)
+	t.Fail()
+}
+
 // TestConcurrencySafety shouldn't output logged values, since there shouldn't be any data races nor invalid concurrenct object accesses.
 func TestConcurrencySafety(t *testing.T) {
 	// tl, _ := setupTestcase(t)
@@ -255,7 +813,8 @@ func TestRaceConditionDuringTest(t *testing.T) {
 	tl, _ := setupTestcase(t)
 	cnt := 100
 	var wg sync.WaitGroup
-	for i := 0; i < cnt; i++ {
+	var i int // NOTE: declared outside of the loop, so that all iterations share the variable
+	for i = 0; i < cnt; i++ {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
@@ -265,3 +824,85 @@ func TestRaceConditionDuringTest(t *testing.T) {
 	wg.Wait()
 	t.FailNow()
 }
+
+// TestLoopLimit should output the log entries made before the loop and during the last b.N iteration, since the benchmark fails.
+// The log entries of the previous iterations are discarded by Loop, so they don't count against the limit nor as dropped.
+func TestLoopLimit(t *testing.T) {
+	_, f := setupTestcase(t)
+	benchtime := flag.Lookup("test.benchtime").Value.String()
+	flag.Set("test.benchtime", "100x")
+	defer flag.Set("test.benchtime", benchtime)
+	testing.Benchmark(func(b *testing.B) {
+		tl := tlog.NewWithWriter(b, f)
+		tl.LimitsTo(tlog.Limit{First: 1, MaxBytes: 40})
+		tl.Log("before loop")
+		i := 0
+		for tl.Loop() {
+			tl.Logf("iteration %03d a", i)
+			tl.Logf("iteration %03d b", i)
+			i++
+		}
+		if b.N > 1 {
+			b.Fail()
+		}
+	})
+}
+
+// BenchmarkLoop shouldn't output anything, since benchmark doesn't fail.
+// When it fails, only the log entries from the last b.N iteration are outputted.
+func BenchmarkLoop(b *testing.B) {
+	tl := tlog.New(b)
+	tl.Log("before loop")
+	i := 0
+	for tl.Loop() {
+		tl.Logf("iteration %v", i)
+		i++
+	}
+}
+
+// TestLogfAllocs shouldn't output anything, since test doesn't fail.
+// It pins the allocations of a log entry in a passing test, where the message is not formatted nor the location resolved.
+func TestLogfAllocs(t *testing.T) {
+	tl := tlog.New(t)
+	allocs := testing.AllocsPerRun(1000, func() {
+		tl.Logf("%v: %v", "iteration", 42)
+	})
+	// NOTE: the log entry and the variadic arguments are allocated, the growth of the slice of log entries is amortized, so 2 allocations are expected
+	if allocs > 3 {
+		t.Errorf("expected at most 3 allocations per log entry, got %v", allocs)
+	}
+}
+
+// BenchmarkLogf shouldn't output anything, since benchmark doesn't fail.
+// It measures the cost of a log entry in a passing test.
+func BenchmarkLogf(b *testing.B) {
+	tl := tlog.New(b)
+	b.ReportAllocs()
+	for tl.Loop() {
+		tl.Logf("%v: %v", "iteration", 42)
+	}
+}
+
+// BenchmarkLogfEager shouldn't output anything, since benchmark doesn't fail.
+// It measures the cost of a log entry in a passing test, when the log messages are formatted eagerly.
+func BenchmarkLogfEager(b *testing.B) {
+	tl := tlog.New(b)
+	tl.FormatsEagerly(true)
+	b.ReportAllocs()
+	for tl.Loop() {
+		tl.Logf("%v: %v", "iteration", 42)
+	}
+}
+
+// FuzzLogs shouldn't output anything, since the fuzz target doesn't fail.
+// When an input fails, the log entries are attached to the failing input.
+func FuzzLogs(f *testing.F) {
+	tl := tlog.New(f)
+	tl.Log("adding seed corpus")
+	f.Add("one", 1)
+	f.Add("two", 2)
+	tl.Fuzz(func(t *testing.T, s string, n int) {
+		tl := tlog.New(t)
+		tl.Log(s, n)
+	})
+}
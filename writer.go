@@ -0,0 +1,86 @@
+// Copyright 2023 Meelis Utt. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package tlog
+
+import (
+	"bytes"
+	"io"
+	"sync"
+	"time"
+)
+
+// lineWriter is an io.Writer that records each written line as a new log entry.
+// Partial lines are buffered until they are completed by subsequent writes or flushed.
+type lineWriter struct {
+	sl       *Logger
+	prefix   string               // prefix added to each message.
+	location string               // location of each log entry, when empty the location is resolved from pcs.
+	pcs      [callerDepth]uintptr // program counters of the callers of Logger.Writer.
+	fields   []Field              // fields of each log entry, in addition to the logger's fields.
+	mu       sync.Mutex
+	buf      []byte
+
+	passthrough  io.Writer // when not nil, the lines written by the testing package are written to it instead, see Logger.CaptureStdio.
+	continuation string    // indentation of the continuation lines of the current t.Log message, see lineWriter.testingLine.
+}
+
+// Write records each complete line as a new log entry and buffers the partial line at the end.
+// It always returns len(p) and nil error.
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.record(string(w.buf[:i]))
+		w.buf = w.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+// flush records the buffered partial line, if there is one, as a new log entry.
+func (w *lineWriter) flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.buf) > 0 {
+		w.record(string(w.buf))
+		w.buf = nil
+	}
+}
+
+// record records the line as a new log entry, or passes it through when it's written by the testing package.
+func (w *lineWriter) record(line string) {
+	if w.passthrough != nil && w.testingLine(line) {
+		io.WriteString(w.passthrough, line+"\n")
+		return
+	}
+	w.sl.mu.Lock()
+	defer w.sl.mu.Unlock()
+	w.sl.add(&Entry{
+		Time:     time.Now(),
+		Location: w.location,
+		pcs:      w.pcs,
+		Name:     w.sl.t.Name(),
+		Level:    LevelInfo,
+		Message:  w.prefix + line,
+		Fields:   joinFields(w.sl.fields, w.fields),
+	})
+}
+
+// Writer returns an io.Writer, that records each written line as a new log entry with the message prefixed by prefix.
+// This enables wiring the logger into components that take an io.Writer for their output, eg exec.Cmd.Stdout.
+// Partial lines are buffered across writes and the remaining partial line is recorded in the test's cleanup.
+// The location of the log entries is the location where Writer was called.
+// As with Log, the entries are only outputted when the test fails or panics.
+// The returned io.Writer can be used simultaneously from multiple goroutines.
+func (sl *Logger) Writer(prefix string) io.Writer {
+	sl.t.Helper()
+	w := &lineWriter{sl: sl, prefix: prefix, pcs: callers()}
+	sl.t.Cleanup(w.flush)
+	return w
+}